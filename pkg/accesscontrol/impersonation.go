@@ -0,0 +1,22 @@
+package accesscontrol
+
+import (
+	"context"
+
+	"github.com/acorn-io/brent/pkg/auth"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+// ImpersonationAuthorizer checks the impersonate verb against the access set of the caller, which is built from RBAC
+// only but does not need a request to the apiserver
+func ImpersonationAuthorizer(asl AccessSetLookup) auth.ImpersonationAuthorizer {
+	return auth.ImpersonationAuthorizerFunc(func(_ context.Context, caller user.Info, attrs auth.ImpersonationAttributes) (bool, error) {
+		resource := attrs.Resource
+		if attrs.Subresource != "" {
+			resource += "/" + attrs.Subresource
+		}
+		gr := schema.GroupResource{Group: attrs.Group, Resource: resource}
+		return asl.AccessFor(caller).Grants("impersonate", gr, attrs.Namespace, attrs.Name), nil
+	})
+}
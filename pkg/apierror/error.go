@@ -1,7 +1,9 @@
 package apierror
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/acorn-io/schemer/validation"
 )
@@ -11,6 +13,8 @@ type APIError struct {
 	Message   string
 	Cause     error
 	FieldName string
+	// Details is returned to the client along with the message
+	Details interface{}
 }
 
 func NewAPIError(code validation.ErrorCode, message string) error {
@@ -36,3 +40,16 @@ func (a *APIError) Error() string {
 	}
 	return fmt.Sprintf("%s: %s", a.Code, a.Message)
 }
+
+// WriteError writes an error response in the same form as the API does, for middlewares that reject a request
+// before the API handles it.
+func WriteError(rw http.ResponseWriter, status int, code, message string) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+		"type":    "error",
+		"status":  status,
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,361 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/auth"
+	"github.com/acorn-io/brent/pkg/stores/empty"
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/data/convert"
+	"github.com/acorn-io/schemer/validation"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const (
+	SecretType  corev1.SecretType = "brent.acorn.io/apikey"
+	LabelAPIKey                   = "brent.acorn.io/apikey"
+	LabelOwner                    = "brent.acorn.io/apikey-owner"
+
+	// DefaultMaxTTL is how long keys are valid for at most if Register is not given a limit
+	DefaultMaxTTL = 30 * 24 * time.Hour
+
+	secretPrefix = "apikey-"
+	idLength     = 8
+	secretLength = 32
+	alphabet     = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	keyTokenHash   = "tokenHash"
+	keyUser        = "user"
+	keyUID         = "uid"
+	keyGroups      = "groups"
+	keyExtra       = "extra"
+	keyDescription = "description"
+	keyExpiresAt   = "expiresAt"
+)
+
+// tokenRegexp matches the <id>.<secret> format of API keys, other bearer tokens are left to other authenticators
+var (
+	tokenRegexp = regexp.MustCompile(`^([a-z0-9]{8})\.([a-z0-9]{32})$`)
+	idRegexp    = regexp.MustCompile(`^[a-z0-9]{8}$`)
+)
+
+// APIKey is a bearer token of the user that created it. Only the hash of the token is stored, so the token is only
+// returned by the create request. The groups and extras of the user are captured when the key is created and are not
+// resolved again, which is why every key expires: no later than the max TTL and the credential it was created with.
+type APIKey struct {
+	Description string   `json:"description,omitempty"`
+	ExpiresAt   string   `json:"expiresAt,omitempty"`
+	TTLSeconds  int64    `json:"ttlSeconds,omitempty" wrangler:"noupdate"`
+	User        string   `json:"user,omitempty" wrangler:"nocreate,noupdate"`
+	Groups      []string `json:"groups,omitempty" wrangler:"nocreate,noupdate"`
+	Created     string   `json:"created,omitempty" wrangler:"nocreate,noupdate"`
+	Expired     bool     `json:"expired,omitempty" wrangler:"nocreate,noupdate"`
+	Token       string   `json:"token,omitempty" wrangler:"nocreate,noupdate"`
+}
+
+// Register adds the apikey schema. Users list, create and delete their own keys, which are stored as Secrets in
+// the namespace of the secrets client. Keys are valid for at most maxTTL, or DefaultMaxTTL if it is not set.
+func Register(schemas *types.APISchemas, secrets corev1client.SecretInterface, maxTTL time.Duration) {
+	if maxTTL <= 0 {
+		maxTTL = DefaultMaxTTL
+	}
+
+	schemas.InternalSchemas.TypeName("apikey", APIKey{})
+	schemas.MustImportAndCustomize(APIKey{}, func(schema *types.APISchema) {
+		schema.CollectionMethods = []string{http.MethodGet, http.MethodPost}
+		schema.ResourceMethods = []string{http.MethodGet, http.MethodDelete}
+		schema.PluralName = "apikeys"
+		schema.Store = &Store{
+			secrets: secrets,
+			maxTTL:  maxTTL,
+			now:     time.Now,
+		}
+	})
+}
+
+type Store struct {
+	empty.Store
+
+	secrets corev1client.SecretInterface
+	maxTTL  time.Duration
+	now     func() time.Time
+}
+
+func (s *Store) List(apiOp *types.APIRequest, schema *types.APISchema) (types.APIObjectList, error) {
+	owner, err := owner(apiOp)
+	if err != nil {
+		return types.APIObjectList{}, err
+	}
+
+	secrets, err := s.secrets.List(apiOp.Context(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=true,%s=%s", LabelAPIKey, LabelOwner, ownerHash(owner.GetName())),
+	})
+	if err != nil {
+		return types.APIObjectList{}, err
+	}
+
+	result := types.APIObjectList{
+		Revision: secrets.ResourceVersion,
+	}
+	for i := range secrets.Items {
+		if isOwner(&secrets.Items[i], owner) {
+			result.Objects = append(result.Objects, s.toAPIObject(schema, &secrets.Items[i], ""))
+		}
+	}
+	return result, nil
+}
+
+func (s *Store) ByID(apiOp *types.APIRequest, schema *types.APISchema, id string) (types.APIObject, error) {
+	secret, err := s.get(apiOp, id)
+	if err != nil {
+		return types.APIObject{}, err
+	}
+	return s.toAPIObject(schema, secret, ""), nil
+}
+
+func (s *Store) Create(apiOp *types.APIRequest, schema *types.APISchema, data types.APIObject) (types.APIObject, error) {
+	owner, err := owner(apiOp)
+	if err != nil {
+		return types.APIObject{}, err
+	}
+	// a key would let the caller keep acting as the impersonated user after the impersonation is no longer allowed
+	if _, ok := auth.ImpersonatorFrom(apiOp.Context()); ok {
+		return types.APIObject{}, apierror.NewAPIError(validation.PermissionDenied, "apikeys can not be created while impersonating")
+	}
+
+	input := data.Data()
+	expiresAt, err := s.expiresAt(owner, input.String("expiresAt"), input["ttlSeconds"])
+	if err != nil {
+		return types.APIObject{}, err
+	}
+
+	groups, err := json.Marshal(owner.GetGroups())
+	if err != nil {
+		return types.APIObject{}, err
+	}
+	extra, err := json.Marshal(owner.GetExtra())
+	if err != nil {
+		return types.APIObject{}, err
+	}
+
+	id, err := randomString(idLength)
+	if err != nil {
+		return types.APIObject{}, err
+	}
+	tokenSecret, err := randomString(secretLength)
+	if err != nil {
+		return types.APIObject{}, err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: secretPrefix + id,
+			Labels: map[string]string{
+				LabelAPIKey: "true",
+				LabelOwner:  ownerHash(owner.GetName()),
+			},
+		},
+		Type: SecretType,
+		StringData: map[string]string{
+			keyTokenHash:   hashToken(tokenSecret),
+			keyUser:        owner.GetName(),
+			keyUID:         owner.GetUID(),
+			keyGroups:      string(groups),
+			keyExtra:       string(extra),
+			keyDescription: input.String("description"),
+			keyExpiresAt:   expiresAt,
+		},
+	}
+
+	secret, err = s.secrets.Create(apiOp.Context(), secret, metav1.CreateOptions{})
+	if err != nil {
+		return types.APIObject{}, err
+	}
+	return s.toAPIObject(schema, secret, id+"."+tokenSecret), nil
+}
+
+func (s *Store) Delete(apiOp *types.APIRequest, schema *types.APISchema, id string) (types.APIObject, error) {
+	secret, err := s.get(apiOp, id)
+	if err != nil {
+		return types.APIObject{}, err
+	}
+
+	err = s.secrets.Delete(apiOp.Context(), secret.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &secret.UID},
+	})
+	if err != nil {
+		return types.APIObject{}, err
+	}
+	return s.toAPIObject(schema, secret, ""), nil
+}
+
+// get returns the key if it exists and is owned by the user, keys of other users are not found
+func (s *Store) get(apiOp *types.APIRequest, id string) (*corev1.Secret, error) {
+	owner, err := owner(apiOp)
+	if err != nil {
+		return nil, err
+	}
+
+	notFound := apierror.NewAPIError(validation.NotFound, "apikey "+id+" not found")
+	if !idRegexp.MatchString(id) {
+		return nil, notFound
+	}
+
+	secret, err := s.secrets.Get(apiOp.Context(), secretPrefix+id, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, notFound
+	} else if err != nil {
+		return nil, err
+	}
+	if !isOwner(secret, owner) {
+		return nil, notFound
+	}
+	return secret, nil
+}
+
+// expiresAt returns when a key expires: as requested, or after the max TTL if nothing is, but never after the
+// credential of the request
+func (s *Store) expiresAt(owner user.Info, expiresAt string, ttlSeconds interface{}) (string, error) {
+	var ttl int64
+	if ttlSeconds != nil {
+		var err error
+		if ttl, err = convert.ToNumber(ttlSeconds); err != nil {
+			return "", apierror.NewAPIError(validation.InvalidFormat, "ttlSeconds must be a number")
+		}
+	}
+
+	var (
+		now    = s.now()
+		result = now.Add(s.maxTTL)
+	)
+	switch {
+	case expiresAt != "" && ttl != 0:
+		return "", apierror.NewAPIError(validation.InvalidOption, "only one of expiresAt and ttlSeconds can be set")
+	case ttl < 0:
+		return "", apierror.NewAPIError(validation.InvalidOption, "ttlSeconds must not be negative")
+	case ttl > 0:
+		result = now.Add(time.Duration(ttl) * time.Second)
+	case expiresAt != "":
+		t, err := time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			return "", apierror.NewAPIError(validation.InvalidFormat, "expiresAt must be an RFC3339 time")
+		}
+		if !t.After(now) {
+			return "", apierror.NewAPIError(validation.InvalidOption, "expiresAt must be in the future")
+		}
+		result = t
+	}
+	if result.After(now.Add(s.maxTTL)) {
+		return "", apierror.NewAPIError(validation.InvalidOption, fmt.Sprintf("apikeys can not be valid for longer than %s", s.maxTTL))
+	}
+
+	if credentialExpiry, ok := auth.CredentialExpiry(owner); ok && result.After(credentialExpiry) {
+		if !credentialExpiry.After(now) {
+			return "", apierror.NewAPIError(validation.PermissionDenied, "the credential of the request has expired")
+		}
+		result = credentialExpiry
+	}
+	return result.UTC().Format(time.RFC3339), nil
+}
+
+func (s *Store) toAPIObject(schema *types.APISchema, secret *corev1.Secret, token string) types.APIObject {
+	key := toAPIKey(secret)
+	key.Token = token
+	key.Created = secret.CreationTimestamp.UTC().Format(time.RFC3339)
+	if expiresAt, ok := expiry(secret); ok && !s.now().Before(expiresAt) {
+		key.Expired = true
+	}
+	return types.APIObject{
+		Type:   schema.ID,
+		ID:     strings.TrimPrefix(secret.Name, secretPrefix),
+		Object: key,
+	}
+}
+
+func toAPIKey(secret *corev1.Secret) APIKey {
+	key := APIKey{
+		Description: secretValue(secret, keyDescription),
+		ExpiresAt:   secretValue(secret, keyExpiresAt),
+		User:        secretValue(secret, keyUser),
+	}
+	_ = json.Unmarshal([]byte(secretValue(secret, keyGroups)), &key.Groups)
+	return key
+}
+
+// secretValue reads Data, or StringData for secrets that have not been through the apiserver
+func secretValue(secret *corev1.Secret, key string) string {
+	if value, ok := secret.Data[key]; ok {
+		return string(value)
+	}
+	return secret.StringData[key]
+}
+
+func expiry(secret *corev1.Secret) (time.Time, bool) {
+	value := secretValue(secret, keyExpiresAt)
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		// an unreadable expiry must not make the key valid forever
+		return time.Time{}, true
+	}
+	return t, true
+}
+
+// owner returns the authenticated user, anonymous users can not have keys
+func owner(apiOp *types.APIRequest) (user.Info, error) {
+	info, ok := request.UserFrom(apiOp.Context())
+	if !ok || info.GetName() == "" || info.GetName() == user.Anonymous {
+		return nil, apierror.NewAPIError(validation.PermissionDenied, "apikeys require an authenticated user")
+	}
+	for _, group := range info.GetGroups() {
+		if group == user.AllUnauthenticated {
+			return nil, apierror.NewAPIError(validation.PermissionDenied, "apikeys require an authenticated user")
+		}
+	}
+	return info, nil
+}
+
+func isOwner(secret *corev1.Secret, owner user.Info) bool {
+	return secret.Type == SecretType && secretValue(secret, keyUser) == owner.GetName()
+}
+
+// ownerHash is the owner label value, user names may be longer than or have characters not allowed in labels
+func ownerHash(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])[:63]
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	max := big.NewInt(int64(len(alphabet)))
+	result := make([]byte, n)
+	for i := range result {
+		c, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		result[i] = alphabet[c.Int64()]
+	}
+	return string(result), nil
+}
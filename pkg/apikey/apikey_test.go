@@ -0,0 +1,257 @@
+package apikey
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/auth"
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func apiRequest(info user.Info) *types.APIRequest {
+	req := httptest.NewRequest(http.MethodGet, "/v1/apikeys", nil)
+	if info != nil {
+		req = req.WithContext(request.WithUser(req.Context(), info))
+	}
+	return &types.APIRequest{Request: req}
+}
+
+func errorCode(err error) validation.ErrorCode {
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		return apiErr.Code
+	}
+	return validation.ErrorCode{}
+}
+
+func apiKeySchema(t *testing.T, client *fake.Clientset) *types.APISchema {
+	schemas := types.EmptyAPISchemas()
+	Register(schemas, client.CoreV1().Secrets("brent"), 24*time.Hour)
+	schema := schemas.LookupSchema("apikeys")
+	require.NotNil(t, schema)
+	return schema
+}
+
+func TestStore(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	schema := apiKeySchema(t, client)
+	store := schema.Store
+
+	alice := apiRequest(&user.DefaultInfo{Name: "alice", UID: "1", Groups: []string{"dev", user.AllAuthenticated}})
+	bob := apiRequest(&user.DefaultInfo{Name: "bob", Groups: []string{user.AllAuthenticated}})
+
+	created, err := store.Create(alice, schema, types.APIObject{Object: map[string]interface{}{
+		"description": "ci",
+		"ttlSeconds":  int64(3600),
+	}})
+	require.NoError(t, err)
+	key := created.Object.(APIKey)
+	assert.Regexp(t, tokenRegexp, key.Token)
+	assert.Equal(t, "alice", key.User)
+	assert.Equal(t, "ci", key.Description)
+	assert.NotEmpty(t, key.ExpiresAt)
+
+	secret, err := client.CoreV1().Secrets("brent").Get(context.Background(), secretPrefix+created.ID, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, hashToken(key.Token[idLength+1:]), secretValue(secret, keyTokenHash))
+
+	list, err := store.List(alice, schema)
+	require.NoError(t, err)
+	if assert.Len(t, list.Objects, 1) {
+		assert.Empty(t, list.Objects[0].Object.(APIKey).Token)
+	}
+
+	list, err = store.List(bob, schema)
+	require.NoError(t, err)
+	assert.Empty(t, list.Objects)
+
+	_, err = store.ByID(bob, schema, created.ID)
+	assert.Equal(t, validation.NotFound, errorCode(err))
+	_, err = store.Delete(bob, schema, created.ID)
+	assert.Equal(t, validation.NotFound, errorCode(err))
+
+	_, err = store.Create(apiRequest(&user.DefaultInfo{Name: user.Anonymous, Groups: []string{user.AllUnauthenticated}}), schema,
+		types.APIObject{Object: map[string]interface{}{}})
+	assert.Equal(t, validation.PermissionDenied, errorCode(err))
+
+	_, err = store.Create(alice, schema, types.APIObject{Object: map[string]interface{}{
+		"expiresAt": time.Now().Add(-time.Hour).Format(time.RFC3339),
+	}})
+	assert.Equal(t, validation.InvalidOption, errorCode(err))
+
+	_, err = store.Delete(alice, schema, created.ID)
+	require.NoError(t, err)
+	list, err = store.List(alice, schema)
+	require.NoError(t, err)
+	assert.Empty(t, list.Objects)
+}
+
+func TestStoreExpiry(t *testing.T) {
+	schema := apiKeySchema(t, fake.NewSimpleClientset())
+	store := schema.Store.(*Store)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time {
+		return now
+	}
+
+	alice := &user.DefaultInfo{Name: "alice", Groups: []string{user.AllAuthenticated}}
+	oidcAlice := &auth.ExpiringUser{Info: alice, ExpiresAt: now.Add(time.Hour)}
+
+	tests := []struct {
+		name     string
+		owner    user.Info
+		input    map[string]interface{}
+		want     string
+		wantCode validation.ErrorCode
+	}{
+		{
+			name:  "max TTL by default",
+			owner: alice,
+			input: map[string]interface{}{},
+			want:  "2024-01-02T00:00:00Z",
+		},
+		{
+			name:  "ttl",
+			owner: alice,
+			input: map[string]interface{}{"ttlSeconds": int64(60)},
+			want:  "2024-01-01T00:01:00Z",
+		},
+		{
+			name:     "longer than max TTL",
+			owner:    alice,
+			input:    map[string]interface{}{"expiresAt": "2024-02-01T00:00:00Z"},
+			wantCode: validation.InvalidOption,
+		},
+		{
+			name:  "capped at the expiry of the credential",
+			owner: oidcAlice,
+			input: map[string]interface{}{"ttlSeconds": int64(7200)},
+			want:  "2024-01-01T01:00:00Z",
+		},
+		{
+			name:  "before the expiry of the credential",
+			owner: oidcAlice,
+			input: map[string]interface{}{"ttlSeconds": int64(60)},
+			want:  "2024-01-01T00:01:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, err := store.Create(apiRequest(tt.owner), schema, types.APIObject{Object: tt.input})
+			if tt.wantCode != (validation.ErrorCode{}) {
+				assert.Equal(t, tt.wantCode, errorCode(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, created.Object.(APIKey).ExpiresAt)
+		})
+	}
+}
+
+func TestStoreImpersonation(t *testing.T) {
+	schema := apiKeySchema(t, fake.NewSimpleClientset())
+	allow := auth.ImpersonationAuthorizerFunc(func(context.Context, user.Info, auth.ImpersonationAttributes) (bool, error) {
+		return true, nil
+	})
+
+	var err error
+	handler := auth.NewImpersonationMiddleware(allow)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err = schema.Store.Create(&types.APIRequest{Request: req}, schema, types.APIObject{Object: map[string]interface{}{}})
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/apikeys", nil)
+	req = req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: "admin", Groups: []string{user.AllAuthenticated}}))
+	req.Header.Set("Impersonate-User", "alice")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, validation.PermissionDenied, errorCode(err))
+}
+
+func TestAuthenticator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := fake.NewSimpleClientset()
+	schema := apiKeySchema(t, client)
+	store := schema.Store
+	alice := apiRequest(&user.DefaultInfo{Name: "alice", UID: "1", Groups: []string{"dev", user.AllAuthenticated}})
+
+	valid, err := store.Create(alice, schema, types.APIObject{Object: map[string]interface{}{}})
+	require.NoError(t, err)
+	expired, err := store.Create(alice, schema, types.APIObject{Object: map[string]interface{}{"ttlSeconds": int64(1)}})
+	require.NoError(t, err)
+	validToken := valid.Object.(APIKey).Token
+	validExpiry, err := time.Parse(time.RFC3339, valid.Object.(APIKey).ExpiresAt)
+	require.NoError(t, err)
+	expiredToken := expired.Object.(APIKey).Token
+
+	a, err := NewAuthenticator(ctx, client, "brent")
+	require.NoError(t, err)
+	a.(*authenticator).now = func() time.Time {
+		return time.Now().Add(time.Minute)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		want    user.Info
+		wantOK  bool
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			token: validToken,
+			want: &auth.ExpiringUser{
+				Info:      &user.DefaultInfo{Name: "alice", UID: "1", Groups: []string{"dev", user.AllAuthenticated}},
+				ExpiresAt: validExpiry,
+			},
+			wantOK: true,
+		},
+		{
+			name:    "expired",
+			token:   expiredToken,
+			wantErr: true,
+		},
+		{
+			name:    "wrong secret",
+			token:   validToken[:idLength+1] + "00000000000000000000000000000000",
+			wantErr: true,
+		},
+		{
+			name:    "unknown id",
+			token:   "00000000.00000000000000000000000000000000",
+			wantErr: true,
+		},
+		{
+			name:  "other token",
+			token: "not-an-apikey",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/pods", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			info, ok, err := a.Authenticate(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, info)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/auth"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+type authenticator struct {
+	secrets corev1listers.SecretNamespaceLister
+	now     func() time.Time
+}
+
+// NewAuthenticator authenticates the API keys stored in the namespace as the user that created them, with the groups
+// and extras the user had then. Keys are read from an informer, so a revoked key stops working as soon as the informer
+// sees the delete.
+func NewAuthenticator(ctx context.Context, client kubernetes.Interface, namespace string) (auth.Authenticator, error) {
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = LabelAPIKey + "=true"
+		}))
+	lister := factory.Core().V1().Secrets().Lister()
+
+	factory.Start(ctx.Done())
+	for _, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return nil, fmt.Errorf("failed to wait for apikey secrets in namespace %s to sync", namespace)
+		}
+	}
+
+	return &authenticator{
+		secrets: lister.Secrets(namespace),
+		now:     time.Now,
+	}, nil
+}
+
+func (a *authenticator) Authenticate(req *http.Request) (user.Info, bool, error) {
+	token := req.Header.Get("Authorization")
+	if !strings.HasPrefix(token, "Bearer ") {
+		return nil, false, nil
+	}
+
+	match := tokenRegexp.FindStringSubmatch(strings.TrimPrefix(token, "Bearer "))
+	if match == nil {
+		return nil, false, nil
+	}
+	id, tokenSecret := match[1], match[2]
+
+	secret, err := a.secrets.Get(secretPrefix + id)
+	if apierrors.IsNotFound(err) {
+		return nil, false, fmt.Errorf("apikey %s not found", id)
+	} else if err != nil {
+		return nil, false, err
+	}
+	if secret.Type != SecretType {
+		return nil, false, fmt.Errorf("apikey %s not found", id)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(tokenSecret)), []byte(secretValue(secret, keyTokenHash))) != 1 {
+		return nil, false, fmt.Errorf("apikey %s is invalid", id)
+	}
+	expiresAt, expires := expiry(secret)
+	if expires && !a.now().Before(expiresAt) {
+		return nil, false, fmt.Errorf("apikey %s expired", id)
+	}
+
+	info := &user.DefaultInfo{
+		Name: secretValue(secret, keyUser),
+		UID:  secretValue(secret, keyUID),
+	}
+	if info.Name == "" {
+		return nil, false, fmt.Errorf("apikey %s has no user", id)
+	}
+	if err := unmarshalValue(secret, keyGroups, &info.Groups); err != nil {
+		return nil, false, fmt.Errorf("apikey %s: %w", id, err)
+	}
+	if err := unmarshalValue(secret, keyExtra, &info.Extra); err != nil {
+		return nil, false, fmt.Errorf("apikey %s: %w", id, err)
+	}
+	if !containsString(info.Groups, user.AllAuthenticated) {
+		info.Groups = append(info.Groups, user.AllAuthenticated)
+	}
+	if expires {
+		// keys created with this key expire no later than it
+		return &auth.ExpiringUser{Info: info, ExpiresAt: expiresAt}, true, nil
+	}
+	return info, true, nil
+}
+
+func unmarshalValue(secret *corev1.Secret, key string, v interface{}) error {
+	value := secretValue(secret, key)
+	if value == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
@@ -154,3 +154,11 @@ func SetPreferredGroup(s *types.APISchema, ver string) {
 	}
 	s.Attributes["preferredGroup"] = ver
 }
+
+func Subresources(s *types.APISchema) []string {
+	return convert.ToStringSlice(s.Attributes["subresources"])
+}
+
+func SetSubresources(s *types.APISchema, subresources []string) {
+	setVal(s, "subresources", subresources)
+}
@@ -0,0 +1,397 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/auth"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// Level is how much of a request is recorded, in the spirit of the Kubernetes audit policy levels
+type Level string
+
+const (
+	// LevelNone does not record the request
+	LevelNone Level = "None"
+	// LevelMetadata records who made the request, what it was for and how it was answered
+	LevelMetadata Level = "Metadata"
+	// LevelRequest also records the request body
+	LevelRequest Level = "Request"
+
+	defaultMaxBodyBytes = 64 * 1024
+)
+
+func (l Level) Less(other Level) bool {
+	return l.ordinal() < other.ordinal()
+}
+
+func (l Level) ordinal() int {
+	switch l {
+	case LevelMetadata:
+		return 1
+	case LevelRequest:
+		return 2
+	}
+	return 0
+}
+
+// ParseLevel parses a level name, the empty string is LevelNone
+func ParseLevel(s string) (Level, error) {
+	switch {
+	case s == "" || strings.EqualFold(s, string(LevelNone)):
+		return LevelNone, nil
+	case strings.EqualFold(s, string(LevelMetadata)):
+		return LevelMetadata, nil
+	case strings.EqualFold(s, string(LevelRequest)):
+		return LevelRequest, nil
+	}
+	return LevelNone, fmt.Errorf("invalid audit level %s, must be None, Metadata or Request", s)
+}
+
+// Event is the record of one request
+type Event struct {
+	Level              Level               `json:"level"`
+	Time               time.Time           `json:"time"`
+	User               string              `json:"user,omitempty"`
+	Groups             []string            `json:"groups,omitempty"`
+	Extra              map[string][]string `json:"extra,omitempty"`
+	Impersonator       string              `json:"impersonator,omitempty"`
+	ImpersonatorGroups []string            `json:"impersonatorGroups,omitempty"`
+	Authenticator      string              `json:"authenticator,omitempty"`
+	SourceIP           string              `json:"sourceIP,omitempty"`
+	Verb               string              `json:"verb"`
+	Method             string              `json:"method"`
+	Path               string              `json:"path"`
+	APIGroup           string              `json:"apiGroup,omitempty"`
+	Resource           string              `json:"resource,omitempty"`
+	Subresource        string              `json:"subresource,omitempty"`
+	Namespace          string              `json:"namespace,omitempty"`
+	Name               string              `json:"name,omitempty"`
+	ResponseCode       int                 `json:"responseCode"`
+	// Latency is the time until the handler returned, for watches and websockets this is how long they were open
+	Latency     time.Duration   `json:"latency"`
+	RequestBody json.RawMessage `json:"requestBody,omitempty"`
+	// RequestBodyTruncated is true if the body was larger than the policy allows and RequestBody is a prefix of it
+	RequestBodyTruncated bool `json:"requestBodyTruncated,omitempty"`
+}
+
+// Sink receives the events of audited requests. Write is called once the request is done, implementations should not
+// block the request for long.
+type Sink interface {
+	Write(event *Event) error
+}
+
+// Policy decides the level each request is recorded at
+type Policy struct {
+	// Level of mutating requests, such as create, update, patch, delete and actions
+	Level Level
+	// ReadLevel of get, list and watch requests
+	ReadLevel Level
+	// MaxBodyBytes is the most of a request body recorded at LevelRequest, 64KiB by default
+	MaxBodyBytes int
+	// TrustedProxies are the addresses whose X-Forwarded-For header is believed. The source IP of other requests is
+	// the address they come from, as anyone can send the header.
+	TrustedProxies []*net.IPNet
+}
+
+// ParseTrustedProxies parses IP addresses and CIDR ranges
+func ParseTrustedProxies(values []string) ([]*net.IPNet, error) {
+	var result []*net.IPNet
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %s, must be an IP address or a CIDR", value)
+			}
+			result = append(result, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+		_, cidr, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %s, must be an IP address or a CIDR", value)
+		}
+		result = append(result, cidr)
+	}
+	return result, nil
+}
+
+func (p Policy) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range p.TrustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	readVerbs = sets.NewString("get", "list", "watch")
+	// bodies of these resources hold credentials and are never recorded
+	sensitiveResources = sets.NewString("secret", "secrets")
+)
+
+func (p Policy) levelFor(event *Event) Level {
+	level := p.Level
+	if readVerbs.Has(event.Verb) {
+		level = p.ReadLevel
+	}
+	switch {
+	case level.ordinal() == 0:
+		return LevelNone
+	case level == LevelRequest && sensitiveResources.Has(event.Resource):
+		return LevelMetadata
+	}
+	return level
+}
+
+func (p Policy) maxLevel() Level {
+	if p.Level.Less(p.ReadLevel) {
+		return p.ReadLevel
+	}
+	return p.Level
+}
+
+func (p Policy) maxBodyBytes() int {
+	if p.MaxBodyBytes > 0 {
+		return p.MaxBodyBytes
+	}
+	return defaultMaxBodyBytes
+}
+
+type eventKey struct{}
+
+// WithEvent returns a context with the event of the request, handlers that know more about the request than its URL
+// fill in the event found with EventFrom
+func WithEvent(ctx context.Context, event *Event) context.Context {
+	return context.WithValue(ctx, eventKey{}, event)
+}
+
+// EventFrom returns the event of the request, if it is audited
+func EventFrom(ctx context.Context) (*Event, bool) {
+	event, ok := ctx.Value(eventKey{}).(*Event)
+	return event, ok
+}
+
+var requestInfoFactory = &request.RequestInfoFactory{
+	APIPrefixes:          sets.NewString("api", "apis"),
+	GrouplessAPIPrefixes: sets.NewString("api"),
+}
+
+// Middleware records the requests of authenticated users to the sink at the level the policy gives them. It must run
+// after authentication. The verb and resource of requests to the Kubernetes API are parsed from the URL unless the
+// handler has already filled them in.
+func Middleware(sink Sink, policy Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			info, ok := request.UserFrom(req.Context())
+			if !ok || policy.maxLevel().ordinal() == 0 {
+				next.ServeHTTP(rw, req)
+				return
+			}
+
+			start := time.Now()
+			event := &Event{
+				Time:     start,
+				User:     info.GetName(),
+				Groups:   info.GetGroups(),
+				Extra:    info.GetExtra(),
+				SourceIP: policy.sourceIP(req),
+				Method:   req.Method,
+				Path:     req.URL.Path,
+			}
+			event.Authenticator, _ = auth.AuthenticatedBy(req.Context())
+			if impersonator, ok := auth.ImpersonatorFrom(req.Context()); ok {
+				event.Impersonator = impersonator.GetName()
+				event.ImpersonatorGroups = impersonator.GetGroups()
+			}
+
+			if policy.maxLevel() == LevelRequest && req.Body != nil && req.Body != http.NoBody {
+				body, truncated, err := peekBody(req, policy.maxBodyBytes())
+				if err != nil {
+					logrus.Debugf("failed to read request body for audit: %v", err)
+				}
+				event.RequestBody, event.RequestBodyTruncated = body, truncated
+			}
+
+			recorder := &responseRecorder{ResponseWriter: rw}
+			next.ServeHTTP(recorder, req.WithContext(WithEvent(req.Context(), event)))
+
+			event.Latency = time.Since(start)
+			event.ResponseCode = recorder.statusCode()
+			if event.Verb == "" {
+				fillFromURL(event, req)
+			}
+
+			event.Level = policy.levelFor(event)
+			if event.Level == LevelNone {
+				return
+			}
+			if event.Level != LevelRequest {
+				event.RequestBody, event.RequestBodyTruncated = nil, false
+			}
+			if err := sink.Write(event); err != nil {
+				logrus.Errorf("failed to write audit event for %s %s: %v", event.Verb, event.Path, err)
+			}
+		})
+	}
+}
+
+// fillFromURL parses the verb and resource of a request to the Kubernetes API
+func fillFromURL(event *Event, req *http.Request) {
+	info, err := requestInfoFactory.NewRequestInfo(req)
+	if err != nil || !info.IsResourceRequest {
+		event.Verb = VerbForMethod(req.Method, true)
+		return
+	}
+	event.Verb = info.Verb
+	event.APIGroup = info.APIGroup
+	event.Resource = info.Resource
+	event.Subresource = info.Subresource
+	event.Namespace = info.Namespace
+	event.Name = info.Name
+}
+
+// VerbForMethod returns the Kubernetes verb of a request method
+func VerbForMethod(method string, named bool) string {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		if named {
+			return "get"
+		}
+		return "list"
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		if named {
+			return "delete"
+		}
+		return "deletecollection"
+	}
+	return strings.ToLower(method)
+}
+
+// peekBody reads up to max bytes of the body and puts them back in front of the rest of it
+func peekBody(req *http.Request, max int) (json.RawMessage, bool, error) {
+	buf := make([]byte, max+1)
+	n, err := io.ReadFull(req.Body, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, false, err
+	}
+	buf = buf[:n]
+	req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(buf), req.Body), Closer: req.Body}
+
+	truncated := n > max
+	if truncated {
+		buf = buf[:max]
+	}
+	if n == 0 {
+		return nil, false, nil
+	}
+	if !truncated && json.Valid(buf) {
+		return append(json.RawMessage{}, buf...), false, nil
+	}
+	// YAML, truncated or otherwise not JSON bodies are recorded as a string
+	body, err := json.Marshal(string(buf))
+	return body, truncated, err
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// sourceIP is the address the request comes from or, if that is a trusted proxy, the last address before the trusted
+// proxies in X-Forwarded-For. Addresses earlier in the header are added by the client and can not be believed.
+func (p Policy) sourceIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	if !p.trusted(host) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		if addr == "" {
+			continue
+		}
+		host = addr
+		if !p.trusted(addr) {
+			break
+		}
+	}
+	return host
+}
+
+// responseRecorder records the status code while passing flushes and hijacks through for streams and websockets
+type responseRecorder struct {
+	http.ResponseWriter
+	code     int
+	hijacked bool
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		if r.code == 0 {
+			r.code = http.StatusOK
+		}
+		flusher.Flush()
+	}
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer of type %T does not implement http.Hijacker", r.ResponseWriter)
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		r.hijacked = true
+	}
+	return conn, rw, err
+}
+
+func (r *responseRecorder) statusCode() int {
+	switch {
+	case r.code != 0:
+		return r.code
+	case r.hijacked:
+		return http.StatusSwitchingProtocols
+	}
+	return http.StatusOK
+}
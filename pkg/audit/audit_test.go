@@ -0,0 +1,243 @@
+package audit
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+type testSink struct {
+	events []*Event
+}
+
+func (t *testSink) Write(event *Event) error {
+	t.events = append(t.events, event)
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		method   string
+		url      string
+		body     string
+		code     int
+		want     *Event
+		wantBody string
+	}{
+		{
+			name:   "create with body",
+			policy: Policy{Level: LevelRequest},
+			method: http.MethodPost,
+			url:    "/api/v1/namespaces/default/configmaps",
+			body:   `{"metadata":{"name":"cm"}}`,
+			code:   http.StatusCreated,
+			want: &Event{
+				Level:        LevelRequest,
+				Verb:         "create",
+				Resource:     "configmaps",
+				Namespace:    "default",
+				ResponseCode: http.StatusCreated,
+				RequestBody:  []byte(`{"metadata":{"name":"cm"}}`),
+			},
+			wantBody: `{"metadata":{"name":"cm"}}`,
+		},
+		{
+			name:   "truncated body",
+			policy: Policy{Level: LevelRequest, MaxBodyBytes: 4},
+			method: http.MethodPatch,
+			url:    "/apis/apps/v1/namespaces/default/deployments/web/scale",
+			body:   `{"spec":{"replicas":2}}`,
+			code:   http.StatusOK,
+			want: &Event{
+				Level:                LevelRequest,
+				Verb:                 "patch",
+				APIGroup:             "apps",
+				Resource:             "deployments",
+				Subresource:          "scale",
+				Namespace:            "default",
+				Name:                 "web",
+				ResponseCode:         http.StatusOK,
+				RequestBody:          []byte(`"{\"sp"`),
+				RequestBodyTruncated: true,
+			},
+			wantBody: `{"spec":{"replicas":2}}`,
+		},
+		{
+			name:   "secret body is not recorded",
+			policy: Policy{Level: LevelRequest},
+			method: http.MethodPut,
+			url:    "/api/v1/namespaces/default/secrets/token",
+			body:   `{"data":{"token":"c2VjcmV0"}}`,
+			code:   http.StatusOK,
+			want: &Event{
+				Level:        LevelMetadata,
+				Verb:         "update",
+				Resource:     "secrets",
+				Namespace:    "default",
+				Name:         "token",
+				ResponseCode: http.StatusOK,
+			},
+			wantBody: `{"data":{"token":"c2VjcmV0"}}`,
+		},
+		{
+			name:   "reads use the read level",
+			policy: Policy{Level: LevelRequest},
+			method: http.MethodGet,
+			url:    "/api/v1/pods",
+			code:   http.StatusOK,
+		},
+		{
+			name:   "read at metadata",
+			policy: Policy{ReadLevel: LevelMetadata},
+			method: http.MethodGet,
+			url:    "/api/v1/namespaces/default/pods?watch=true",
+			code:   http.StatusForbidden,
+			want: &Event{
+				Level:        LevelMetadata,
+				Verb:         "watch",
+				Resource:     "pods",
+				Namespace:    "default",
+				ResponseCode: http.StatusForbidden,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody string
+			sink := &testSink{}
+			handler := Middleware(sink, tt.policy)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, _ := io.ReadAll(req.Body)
+				gotBody = string(body)
+				rw.WriteHeader(tt.code)
+			}))
+
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req = req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: "alice", Groups: []string{"dev"}}))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.wantBody, gotBody)
+			if tt.want == nil {
+				assert.Empty(t, sink.events)
+				return
+			}
+			if !assert.Len(t, sink.events, 1) {
+				return
+			}
+
+			got := sink.events[0]
+			assert.Equal(t, "alice", got.User)
+			assert.Equal(t, []string{"dev"}, got.Groups)
+			assert.Equal(t, tt.method, got.Method)
+			assert.Equal(t, tt.want.Level, got.Level)
+			assert.Equal(t, tt.want.Verb, got.Verb)
+			assert.Equal(t, tt.want.APIGroup, got.APIGroup)
+			assert.Equal(t, tt.want.Resource, got.Resource)
+			assert.Equal(t, tt.want.Subresource, got.Subresource)
+			assert.Equal(t, tt.want.Namespace, got.Namespace)
+			assert.Equal(t, tt.want.Name, got.Name)
+			assert.Equal(t, tt.want.ResponseCode, got.ResponseCode)
+			assert.Equal(t, string(tt.want.RequestBody), string(got.RequestBody))
+			assert.Equal(t, tt.want.RequestBodyTruncated, got.RequestBodyTruncated)
+		})
+	}
+}
+
+func TestHandlerFilledEvent(t *testing.T) {
+	sink := &testSink{}
+	handler := Middleware(sink, Policy{Level: LevelMetadata})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		event, ok := EventFrom(req.Context())
+		assert.True(t, ok)
+		event.Verb = "delete"
+		event.Resource = "pod"
+		event.Namespace = "default"
+		event.Name = "web"
+	}))
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/pods/default/web", nil)
+	req = req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: "alice"}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if assert.Len(t, sink.events, 1) {
+		assert.Equal(t, "pod", sink.events[0].Resource)
+		assert.Equal(t, "web", sink.events[0].Name)
+		assert.Equal(t, http.StatusOK, sink.events[0].ResponseCode)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sink := NewWriterSink(buf)
+	assert.NoError(t, sink.Write(&Event{Level: LevelMetadata, Verb: "create", Method: "POST", Path: "/v1/pods"}))
+	assert.NoError(t, sink.Write(&Event{Level: LevelMetadata, Verb: "delete", Method: "DELETE", Path: "/v1/pods"}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[1], `"verb":"delete"`)
+}
+
+func TestSourceIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.1", "192.168.0.0/16"})
+	require.NoError(t, err)
+	policy := Policy{TrustedProxies: trusted}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{
+			name:       "direct",
+			remoteAddr: "203.0.113.5:1234",
+			want:       "203.0.113.5",
+		},
+		{
+			name:       "forged by an untrusted client",
+			remoteAddr: "203.0.113.5:1234",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "203.0.113.5",
+		},
+		{
+			name:       "trusted proxy",
+			remoteAddr: "10.0.0.1:1234",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "client entries before the trusted proxies are ignored",
+			remoteAddr: "10.0.0.1:1234",
+			forwarded:  []string{"1.2.3.4, 198.51.100.1", "192.168.1.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "trusted proxy without the header",
+			remoteAddr: "10.0.0.1:1234",
+			want:       "10.0.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/pods", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			assert.Equal(t, tt.want, policy.sourceIP(req))
+		})
+	}
+
+	_, err = ParseTrustedProxies([]string{"proxy.example.com"})
+	assert.Error(t, err)
+}
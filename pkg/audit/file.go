@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// FileSink writes one JSON event per line
+type FileSink struct {
+	lock   sync.Mutex
+	writer io.Writer
+}
+
+// NewFileSink appends events to the file at path, creating it if needed. A path of "-" writes to stdout.
+func NewFileSink(path string) (*FileSink, error) {
+	if path == "-" {
+		return NewWriterSink(os.Stdout), nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterSink(f), nil
+}
+
+func NewWriterSink(writer io.Writer) *FileSink {
+	return &FileSink{
+		writer: writer,
+	}
+}
+
+func (f *FileSink) Write(event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	_, err = f.writer.Write(append(data, '\n'))
+	return err
+}
+
+// Sinks writes every event to each of the sinks
+type Sinks []Sink
+
+func (s Sinks) Write(event *Event) error {
+	var errs []error
+	for _, sink := range s {
+		if err := sink.Write(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	webhookBufferSize = 1000
+	webhookBatchSize  = 100
+	webhookBatchWait  = time.Second
+)
+
+// WebhookSink posts batches of events to a URL as {"items": [...]}. Events are queued so requests are not held up by
+// the webhook, if the queue is full because the webhook is down or too slow events are dropped.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+	events chan *Event
+}
+
+type webhookBatch struct {
+	Items []*Event `json:"items"`
+}
+
+// NewWebhookSink starts sending events to url until ctx is done
+func NewWebhookSink(ctx context.Context, url string) *WebhookSink {
+	w := &WebhookSink{
+		url: url,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		events: make(chan *Event, webhookBufferSize),
+	}
+	go w.run(ctx)
+	return w
+}
+
+func (w *WebhookSink) Write(event *Event) error {
+	select {
+	case w.events <- event:
+		return nil
+	default:
+		return fmt.Errorf("audit webhook queue is full, dropping event")
+	}
+}
+
+func (w *WebhookSink) run(ctx context.Context) {
+	var (
+		batch []*Event
+		timer = time.NewTimer(webhookBatchWait)
+	)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-w.events:
+			batch = append(batch, event)
+			if len(batch) < webhookBatchSize {
+				continue
+			}
+		case <-timer.C:
+			timer.Reset(webhookBatchWait)
+			if len(batch) == 0 {
+				continue
+			}
+		}
+
+		if err := w.send(ctx, batch); err != nil {
+			logrus.Errorf("failed to send %d audit events to webhook: %v", len(batch), err)
+		}
+		batch = nil
+	}
+}
+
+func (w *WebhookSink) send(ctx context.Context, batch []*Event) error {
+	data, err := json.Marshal(webhookBatch{Items: batch})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
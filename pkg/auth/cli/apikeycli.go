@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/apikey"
+	"github.com/acorn-io/brent/pkg/auth"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+type APIKeyConfig struct {
+	APIKeyNamespace     string `name:"apikey-namespace" usage:"Namespace the Secrets of API keys are stored in, enables the apikeys API and API key authentication"`
+	APIKeyMaxTTLSeconds int    `name:"apikey-max-ttl-seconds" default:"2592000" usage:"Longest an API key can be valid for"`
+}
+
+func (a *APIKeyConfig) APIKeyEnabled() bool {
+	return a.APIKeyNamespace != ""
+}
+
+func (a *APIKeyConfig) APIKeyMaxTTL() time.Duration {
+	return time.Duration(a.APIKeyMaxTTLSeconds) * time.Second
+}
+
+func (a *APIKeyConfig) APIKeyAuthenticator(ctx context.Context, restConfig *rest.Config) (auth.Authenticator, error) {
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return apikey.NewAuthenticator(ctx, client, a.APIKeyNamespace)
+}
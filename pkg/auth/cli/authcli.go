@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/acorn-io/brent/pkg/auth"
+	"k8s.io/client-go/rest"
+)
+
+type StaticTokenConfig struct {
+	TokenAuthFile string `name:"token-auth-file" usage:"CSV file of static bearer tokens as token,user,uid,\"group1,group2\""`
+}
+
+func (s *StaticTokenConfig) StaticTokenAuthenticator() (auth.Authenticator, error) {
+	return auth.NewStaticTokenAuthenticator(s.TokenAuthFile)
+}
+
+// Config enables any combination of authenticators, which are tried in the order webhook, OIDC, client certificate,
+// static tokens and API keys
+type Config struct {
+	WebhookConfig
+	OIDCConfig
+	ClientCertConfig
+	StaticTokenConfig
+	APIKeyConfig
+
+	AnonymousAuth string `name:"anonymous-auth" default:"allow" usage:"Requests without credentials are allowed as system:anonymous or denied with 401: allow or deny"`
+}
+
+func (c *Config) Enabled() bool {
+	return c.WebhookAuthentication || c.OIDCEnabled() || c.ClientCertEnabled() || c.TokenAuthFile != "" || c.APIKeyEnabled()
+}
+
+// Middleware returns the union of the enabled authenticators, or nil if none is. The rest config is used to read the
+// Secrets of API keys.
+func (c *Config) Middleware(ctx context.Context, restConfig *rest.Config) (auth.Middleware, error) {
+	if !c.Enabled() {
+		return nil, nil
+	}
+
+	anonymous, err := auth.ParseAnonymousPolicy(c.AnonymousAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	var authenticators []auth.NamedAuthenticator
+	for _, a := range []struct {
+		name    string
+		enabled bool
+		new     func() (auth.Authenticator, error)
+	}{
+		{name: "webhook", enabled: c.WebhookAuthentication, new: c.WebhookAuthenticator},
+		{name: "oidc", enabled: c.OIDCEnabled(), new: func() (auth.Authenticator, error) { return c.OIDCAuthenticator(ctx) }},
+		{name: "clientcert", enabled: c.ClientCertEnabled(), new: c.ClientCertAuthenticator},
+		{name: "token", enabled: c.TokenAuthFile != "", new: c.StaticTokenAuthenticator},
+		{name: "apikey", enabled: c.APIKeyEnabled(), new: func() (auth.Authenticator, error) { return c.APIKeyAuthenticator(ctx, restConfig) }},
+	} {
+		if !a.enabled {
+			continue
+		}
+		authenticator, err := a.new()
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, auth.NamedAuthenticator{Name: a.name, Authenticator: authenticator})
+	}
+
+	return auth.NewUnionMiddleware(anonymous, authenticators...), nil
+}
@@ -0,0 +1,17 @@
+package cli
+
+import (
+	"github.com/acorn-io/brent/pkg/auth"
+)
+
+type ClientCertConfig struct {
+	ClientCAFile string `name:"client-ca-file" usage:"CA bundle that verifies client certificates, enables client certificate authentication over HTTPS"`
+}
+
+func (c *ClientCertConfig) ClientCertEnabled() bool {
+	return c.ClientCAFile != ""
+}
+
+func (c *ClientCertConfig) ClientCertAuthenticator() (auth.Authenticator, error) {
+	return auth.NewClientCertAuthenticator(c.ClientCAFile)
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/auth"
+)
+
+type OIDCConfig struct {
+	OIDCIssuerURL          string            `name:"oidc-issuer-url" usage:"Issuer of the OIDC ID tokens to accept, enables OIDC authentication"`
+	OIDCAudiences          []string          `name:"oidc-audience" usage:"Audience, usually the client ID, one of which the tokens must be for"`
+	OIDCJWKSFile           string            `name:"oidc-jwks-file" usage:"File with the JWKS of the issuer"`
+	OIDCJWKSURL            string            `name:"oidc-jwks-url" usage:"URL of the JWKS of the issuer"`
+	OIDCJWKSRefreshSeconds int               `name:"oidc-jwks-refresh-seconds" default:"3600" usage:"How often the JWKS is loaded again"`
+	OIDCUsernameClaim      string            `name:"oidc-username-claim" default:"sub" usage:"Claim of the username"`
+	OIDCUsernamePrefix     string            `name:"oidc-username-prefix" usage:"Prefix added to usernames"`
+	OIDCGroupsClaim        string            `name:"oidc-groups-claim" default:"groups" usage:"Claim of the groups"`
+	OIDCGroupsPrefix       string            `name:"oidc-groups-prefix" usage:"Prefix added to groups"`
+	OIDCExtraClaims        map[string]string `name:"oidc-extra-claims" usage:"Extra keys and the claims they are read from, as key=claim"`
+}
+
+func (o *OIDCConfig) OIDCEnabled() bool {
+	return o.OIDCIssuerURL != ""
+}
+
+func (o *OIDCConfig) OIDCOptions() auth.OIDCOptions {
+	return auth.OIDCOptions{
+		IssuerURL:      o.OIDCIssuerURL,
+		Audiences:      o.OIDCAudiences,
+		JWKSFile:       o.OIDCJWKSFile,
+		JWKSURL:        o.OIDCJWKSURL,
+		JWKSRefresh:    time.Duration(o.OIDCJWKSRefreshSeconds) * time.Second,
+		UsernameClaim:  o.OIDCUsernameClaim,
+		UsernamePrefix: o.OIDCUsernamePrefix,
+		GroupsClaim:    o.OIDCGroupsClaim,
+		GroupsPrefix:   o.OIDCGroupsPrefix,
+		ExtraClaims:    o.OIDCExtraClaims,
+	}
+}
+
+func (o *OIDCConfig) OIDCAuthenticator(ctx context.Context) (auth.Authenticator, error) {
+	return auth.NewOIDCAuthenticator(ctx, o.OIDCOptions())
+}
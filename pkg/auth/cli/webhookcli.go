@@ -28,7 +28,14 @@ func (w *WebhookConfig) WebhookMiddleware() (auth.Middleware, error) {
 	if !w.WebhookAuthentication {
 		return nil, nil
 	}
+	authenticator, err := w.WebhookAuthenticator()
+	if err != nil {
+		return nil, err
+	}
+	return auth.ToMiddleware(authenticator), nil
+}
 
+func (w *WebhookConfig) WebhookAuthenticator() (auth.Authenticator, error) {
 	config := w.WebhookKubeconfig
 	if config == "" && w.WebhookURL != "" {
 		tempFile, err := auth.WebhookConfigForURL(w.WebhookURL)
@@ -45,5 +52,5 @@ func (w *WebhookConfig) WebhookMiddleware() (auth.Middleware, error) {
 	}
 
 	kubeConfig.RateLimiter = ratelimit.None
-	return auth.NewWebhookMiddleware(time.Duration(w.WebhookCacheTTLSeconds)*time.Second, kubeConfig)
+	return auth.NewWebhookAuthenticator(time.Duration(w.WebhookCacheTTLSeconds)*time.Second, kubeConfig)
 }
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+// how often the client CA file is checked for changes
+const clientCAReloadInterval = 10 * time.Second
+
+type clientCertAuthenticator struct {
+	caFile string
+
+	lock      sync.Mutex
+	pool      *x509.CertPool
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewClientCertAuthenticator authenticates requests with a client certificate signed by a CA in caFile, the common
+// name is the user and the organizations are the groups. The file is read again when it changes. The TLS server only
+// needs to request client certificates, they are verified here.
+func NewClientCertAuthenticator(caFile string) (Authenticator, error) {
+	c := &clientCertAuthenticator{
+		caFile: caFile,
+	}
+	if _, err := c.certPool(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *clientCertAuthenticator) Authenticate(req *http.Request) (user.Info, bool, error) {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return nil, false, nil
+	}
+
+	pool, err := c.certPool()
+	if err != nil {
+		return nil, false, err
+	}
+
+	cert := req.TLS.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range req.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(intermediate)
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return nil, false, fmt.Errorf("invalid client certificate %q: %w", cert.Subject.CommonName, err)
+	}
+
+	if cert.Subject.CommonName == "" {
+		return nil, false, fmt.Errorf("client certificate has no common name")
+	}
+
+	return &ExpiringUser{
+		Info: &user.DefaultInfo{
+			Name:   cert.Subject.CommonName,
+			Groups: append(append([]string{}, cert.Subject.Organization...), user.AllAuthenticated),
+		},
+		ExpiresAt: cert.NotAfter,
+	}, true, nil
+}
+
+// certPool returns the CAs, reading the file again if it changed. If it can no longer be read the last CAs are kept.
+func (c *clientCertAuthenticator) certPool() (*x509.CertPool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.pool != nil && time.Since(c.lastCheck) < clientCAReloadInterval {
+		return c.pool, nil
+	}
+	c.lastCheck = time.Now()
+
+	pool, modTime, err := c.load()
+	if err != nil {
+		if c.pool == nil {
+			return nil, err
+		}
+		logrus.Errorf("failed to reload client CA file %s, keeping the current CAs: %v", c.caFile, err)
+		return c.pool, nil
+	}
+	if pool != nil {
+		c.pool, c.modTime = pool, modTime
+	}
+	return c.pool, nil
+}
+
+// load reads the CAs, or returns a nil pool if the file has not changed
+func (c *clientCertAuthenticator) load() (*x509.CertPool, time.Time, error) {
+	stat, err := os.Stat(c.caFile)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if c.pool != nil && stat.ModTime().Equal(c.modTime) {
+		return nil, time.Time{}, nil
+	}
+
+	data, err := os.ReadFile(c.caFile)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, time.Time{}, fmt.Errorf("no certificates found in client CA file %s", c.caFile)
+	}
+	return pool, stat.ModTime(), nil
+}
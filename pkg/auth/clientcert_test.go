@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return testCA{cert: cert, key: key}
+}
+
+func (c testCA) issue(t *testing.T, subject pkix.Name, usage x509.ExtKeyUsage) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, c.cert, &key.PublicKey, c.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func (c testCA) pem() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+}
+
+func TestClientCertAuthenticator(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	otherCA := newTestCA(t, "other")
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, ca.pem(), 0600))
+
+	authenticator, err := NewClientCertAuthenticator(caFile)
+	require.NoError(t, err)
+
+	alice := ca.issue(t, pkix.Name{CommonName: "alice", Organization: []string{"dev", "ops"}}, x509.ExtKeyUsageClientAuth)
+	serverCert := ca.issue(t, pkix.Name{CommonName: "server"}, x509.ExtKeyUsageServerAuth)
+	noName := ca.issue(t, pkix.Name{Organization: []string{"dev"}}, x509.ExtKeyUsageClientAuth)
+	bob := otherCA.issue(t, pkix.Name{CommonName: "bob"}, x509.ExtKeyUsageClientAuth)
+
+	tests := []struct {
+		name    string
+		certs   []*x509.Certificate
+		want    user.Info
+		wantErr bool
+	}{
+		{
+			name:  "verified",
+			certs: []*x509.Certificate{alice},
+			want: &ExpiringUser{
+				Info: &user.DefaultInfo{
+					Name:   "alice",
+					Groups: []string{"dev", "ops", user.AllAuthenticated},
+				},
+				ExpiresAt: alice.NotAfter,
+			},
+		},
+		{
+			name: "no certificate",
+		},
+		{
+			name:    "unknown CA",
+			certs:   []*x509.Certificate{bob},
+			wantErr: true,
+		},
+		{
+			name:    "not for client auth",
+			certs:   []*x509.Certificate{serverCert},
+			wantErr: true,
+		},
+		{
+			name:    "no common name",
+			certs:   []*x509.Certificate{noName},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "https://brent/v1/pods", nil)
+			req.TLS = &tls.ConnectionState{PeerCertificates: tt.certs}
+
+			info, ok, err := authenticator.Authenticate(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.False(t, ok)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want != nil, ok)
+			assert.Equal(t, tt.want, info)
+		})
+	}
+
+	t.Run("reload", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(caFile, otherCA.pem(), 0600))
+		modTime := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(caFile, modTime, modTime))
+		authenticator.(*clientCertAuthenticator).lastCheck = time.Time{}
+
+		req := httptest.NewRequest(http.MethodGet, "https://brent/v1/pods", nil)
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{bob}}
+		info, ok, err := authenticator.Authenticate(req)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "bob", info.GetName())
+	})
+}
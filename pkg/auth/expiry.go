@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"time"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+// ExpiringUser is a user authenticated by a credential that expires, so whatever is derived from the credential, such
+// as an API key, can be made to expire no later
+type ExpiringUser struct {
+	user.Info
+	ExpiresAt time.Time
+}
+
+// CredentialExpiry returns when the credential the user was authenticated with expires, if it is known
+func CredentialExpiry(info user.Info) (time.Time, bool) {
+	if expiring, ok := info.(*ExpiringUser); ok && !expiring.ExpiresAt.IsZero() {
+		return expiring.ExpiresAt, true
+	}
+	return time.Time{}, false
+}
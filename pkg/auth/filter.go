@@ -185,6 +185,8 @@ func AlwaysAdmin(req *http.Request) (user.Info, bool, error) {
 	}, true, nil
 }
 
+// Impersonation trusts the Impersonate-* headers as they are, so it must only be used behind a proxy that sets them.
+// NewImpersonationMiddleware checks that the caller may impersonate.
 func Impersonation(req *http.Request) (user.Info, bool, error) {
 	userName := req.Header.Get(transport.ImpersonateUserHeader)
 	if userName == "" {
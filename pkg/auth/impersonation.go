@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/sirupsen/logrus"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/client-go/transport"
+)
+
+const impersonateVerb = "impersonate"
+
+// ImpersonationAttributes is what a caller asks to impersonate, in the terms of the RBAC rules that allow it: users,
+// groups and serviceaccounts in the core group, uids and userextras in authentication.k8s.io
+type ImpersonationAttributes struct {
+	Group       string
+	Resource    string
+	Subresource string
+	Namespace   string
+	Name        string
+}
+
+func (a ImpersonationAttributes) String() string {
+	resource := a.Resource
+	if a.Subresource != "" {
+		resource += "/" + a.Subresource
+	}
+	if a.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", resource, a.Namespace, a.Name)
+	}
+	return fmt.Sprintf("%s %s", resource, a.Name)
+}
+
+// ImpersonationAuthorizer decides if the caller holds the impersonate verb on what it asks to impersonate
+type ImpersonationAuthorizer interface {
+	CanImpersonate(ctx context.Context, caller user.Info, attrs ImpersonationAttributes) (bool, error)
+}
+
+type ImpersonationAuthorizerFunc func(ctx context.Context, caller user.Info, attrs ImpersonationAttributes) (bool, error)
+
+func (i ImpersonationAuthorizerFunc) CanImpersonate(ctx context.Context, caller user.Info, attrs ImpersonationAttributes) (bool, error) {
+	return i(ctx, caller, attrs)
+}
+
+// NewSubjectAccessReviewAuthorizer asks the apiserver with a SubjectAccessReview, so every authorizer of the cluster
+// is taken into account and not only RBAC
+func NewSubjectAccessReviewAuthorizer(client authorizationv1client.SubjectAccessReviewsGetter) ImpersonationAuthorizer {
+	return ImpersonationAuthorizerFunc(func(ctx context.Context, caller user.Info, attrs ImpersonationAttributes) (bool, error) {
+		extra := map[string]authorizationv1.ExtraValue{}
+		for k, v := range caller.GetExtra() {
+			extra[k] = v
+		}
+
+		review, err := client.SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   caller.GetName(),
+				UID:    caller.GetUID(),
+				Groups: caller.GetGroups(),
+				Extra:  extra,
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:        impersonateVerb,
+					Group:       attrs.Group,
+					Resource:    attrs.Resource,
+					Subresource: attrs.Subresource,
+					Namespace:   attrs.Namespace,
+					Name:        attrs.Name,
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return false, err
+		}
+		return review.Status.Allowed, nil
+	})
+}
+
+type impersonatorKey struct{}
+
+// ImpersonatorFrom returns the authenticated caller of a request that impersonates the user in the context
+func ImpersonatorFrom(ctx context.Context) (user.Info, bool) {
+	info, ok := ctx.Value(impersonatorKey{}).(user.Info)
+	return info, ok
+}
+
+// NewImpersonationMiddleware serves requests with Impersonate-User, Impersonate-Uid, Impersonate-Group and
+// Impersonate-Extra-* headers as the impersonated user, once the authorizer confirms the authenticated caller may
+// impersonate each of them. It must run after authentication. The caller is kept in the context, see
+// ImpersonatorFrom.
+func NewImpersonationMiddleware(authorizer ImpersonationAuthorizer) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			requested, checks, err := impersonationRequest(req)
+			if err != nil {
+				apierror.WriteError(rw, http.StatusBadRequest, "BadRequest", err.Error())
+				return
+			} else if requested == nil {
+				next.ServeHTTP(rw, req)
+				return
+			}
+
+			caller, ok := request.UserFrom(req.Context())
+			if !ok {
+				unauthorized(rw, "authentication required")
+				return
+			}
+
+			for _, attrs := range checks {
+				allowed, err := authorizer.CanImpersonate(req.Context(), caller, attrs)
+				if err != nil {
+					logrus.Errorf("failed to check if %s can impersonate %s: %v", caller.GetName(), attrs, err)
+					apierror.WriteError(rw, http.StatusInternalServerError, "ServerError", "failed to check impersonation")
+					return
+				}
+				if !allowed {
+					apierror.WriteError(rw, http.StatusForbidden, "Forbidden",
+						fmt.Sprintf("user %q cannot impersonate %s", caller.GetName(), attrs))
+					return
+				}
+			}
+
+			req = req.Clone(request.WithUser(context.WithValue(req.Context(), impersonatorKey{}, caller), requested))
+			for k := range req.Header {
+				if strings.HasPrefix(k, "Impersonate-") {
+					delete(req.Header, k)
+				}
+			}
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// impersonationRequest returns the user a request asks to impersonate and what the caller must be allowed to
+// impersonate, or nil if it does not impersonate
+func impersonationRequest(req *http.Request) (*user.DefaultInfo, []ImpersonationAttributes, error) {
+	var (
+		requested = &user.DefaultInfo{
+			Name:   req.Header.Get(transport.ImpersonateUserHeader),
+			UID:    req.Header.Get(transport.ImpersonateUIDHeader),
+			Groups: req.Header.Values(transport.ImpersonateGroupHeader),
+		}
+		checks []ImpersonationAttributes
+	)
+
+	for k, values := range req.Header {
+		if !strings.HasPrefix(k, transport.ImpersonateUserExtraHeaderPrefix) {
+			continue
+		}
+		key, err := url.PathUnescape(strings.ToLower(k[len(transport.ImpersonateUserExtraHeaderPrefix):]))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid impersonate extra key %q: %w", k, err)
+		}
+		if requested.Extra == nil {
+			requested.Extra = map[string][]string{}
+		}
+		requested.Extra[key] = append(requested.Extra[key], values...)
+		for _, value := range values {
+			checks = append(checks, ImpersonationAttributes{
+				Group:       "authentication.k8s.io",
+				Resource:    "userextras",
+				Subresource: key,
+				Name:        value,
+			})
+		}
+	}
+
+	if requested.Name == "" {
+		if len(requested.Groups) > 0 || requested.UID != "" || len(requested.Extra) > 0 {
+			return nil, nil, fmt.Errorf("%s is required to impersonate groups, uid or extras", transport.ImpersonateUserHeader)
+		}
+		return nil, nil, nil
+	}
+
+	if namespace, name, err := serviceaccount.SplitUsername(requested.Name); err == nil {
+		checks = append(checks, ImpersonationAttributes{Resource: "serviceaccounts", Namespace: namespace, Name: name})
+		requested.Groups = append(requested.Groups, serviceaccount.MakeGroupNames(namespace)...)
+	} else {
+		checks = append(checks, ImpersonationAttributes{Resource: "users", Name: requested.Name})
+	}
+	for _, group := range req.Header.Values(transport.ImpersonateGroupHeader) {
+		checks = append(checks, ImpersonationAttributes{Resource: "groups", Name: group})
+	}
+	if requested.UID != "" {
+		checks = append(checks, ImpersonationAttributes{Group: "authentication.k8s.io", Resource: "uids", Name: requested.UID})
+	}
+
+	if requested.Name != user.Anonymous {
+		requested.Groups = append(requested.Groups, user.AllAuthenticated)
+	} else {
+		requested.Groups = append(requested.Groups, user.AllUnauthenticated)
+	}
+	return requested, checks, nil
+}
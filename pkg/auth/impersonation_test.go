@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestImpersonationMiddleware(t *testing.T) {
+	caller := &user.DefaultInfo{Name: "admin", Groups: []string{"ops"}}
+
+	// admin may impersonate alice, bob's service account, the dev group and the scopes extra "read"
+	allowed := map[ImpersonationAttributes]bool{
+		{Resource: "users", Name: "alice"}:                                                            true,
+		{Resource: "serviceaccounts", Namespace: "default", Name: "bob"}:                              true,
+		{Resource: "groups", Name: "dev"}:                                                             true,
+		{Group: "authentication.k8s.io", Resource: "userextras", Subresource: "scopes", Name: "read"}: true,
+	}
+	authorizer := ImpersonationAuthorizerFunc(func(_ context.Context, info user.Info, attrs ImpersonationAttributes) (bool, error) {
+		return info.GetName() == "admin" && allowed[attrs], nil
+	})
+
+	tests := []struct {
+		name             string
+		headers          map[string][]string
+		wantCode         int
+		wantUser         user.Info
+		wantImpersonator bool
+	}{
+		{
+			name:     "no impersonation",
+			wantCode: http.StatusOK,
+			wantUser: caller,
+		},
+		{
+			name: "user, group and extra",
+			headers: map[string][]string{
+				"Impersonate-User":         {"alice"},
+				"Impersonate-Group":        {"dev"},
+				"Impersonate-Extra-Scopes": {"read"},
+			},
+			wantCode: http.StatusOK,
+			wantUser: &user.DefaultInfo{
+				Name:   "alice",
+				Groups: []string{"dev", user.AllAuthenticated},
+				Extra:  map[string][]string{"scopes": {"read"}},
+			},
+			wantImpersonator: true,
+		},
+		{
+			name: "service account",
+			headers: map[string][]string{
+				"Impersonate-User": {"system:serviceaccount:default:bob"},
+			},
+			wantCode: http.StatusOK,
+			wantUser: &user.DefaultInfo{
+				Name:   "system:serviceaccount:default:bob",
+				Groups: []string{"system:serviceaccounts", "system:serviceaccounts:default", user.AllAuthenticated},
+			},
+			wantImpersonator: true,
+		},
+		{
+			name: "group not allowed",
+			headers: map[string][]string{
+				"Impersonate-User":  {"alice"},
+				"Impersonate-Group": {"system:masters"},
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "user not allowed",
+			headers: map[string][]string{
+				"Impersonate-User": {"carol"},
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "group without user",
+			headers: map[string][]string{
+				"Impersonate-Group": {"dev"},
+			},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				gotUser         user.Info
+				gotImpersonator user.Info
+				gotHeader       string
+			)
+			handler := NewImpersonationMiddleware(authorizer)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				gotUser, _ = request.UserFrom(req.Context())
+				gotImpersonator, _ = ImpersonatorFrom(req.Context())
+				gotHeader = req.Header.Get("Impersonate-User")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/pods", nil)
+			for k, v := range tt.headers {
+				req.Header[k] = v
+			}
+			req = req.WithContext(request.WithUser(req.Context(), caller))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantUser, gotUser)
+			assert.Empty(t, gotHeader)
+			if tt.wantImpersonator {
+				assert.Equal(t, caller, gotImpersonator)
+			} else {
+				assert.Nil(t, gotImpersonator)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var errNotJWT = errors.New("not a JWT")
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// jwt is a parsed compact JWS whose signature has not been checked yet
+type jwt struct {
+	header    jwtHeader
+	claims    map[string]interface{}
+	signed    []byte
+	signature []byte
+}
+
+func parseJWT(token string) (*jwt, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errNotJWT
+	}
+
+	result := &jwt{
+		signed: []byte(parts[0] + "." + parts[1]),
+	}
+	if err := decodeSegment(parts[0], &result.header); err != nil {
+		return nil, errNotJWT
+	}
+	if err := decodeSegment(parts[1], &result.claims); err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+
+	var err error
+	if result.signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, fmt.Errorf("invalid JWT signature: %w", err)
+	}
+	return result, nil
+}
+
+func decodeSegment(segment string, into interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	return dec.Decode(into)
+}
+
+var jwtHashes = map[string]crypto.Hash{
+	"256": crypto.SHA256,
+	"384": crypto.SHA384,
+	"512": crypto.SHA512,
+}
+
+// jwtCurves is the only curve each ECDSA algorithm may be used with (RFC 7518 section 3.4)
+var jwtCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// verify checks the signature with the key. Only asymmetric algorithms are accepted, so a token can not be signed
+// with a public key or with none.
+func (j *jwt) verify(key crypto.PublicKey) error {
+	alg := j.header.Alg
+	if alg == "EdDSA" {
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(pub, j.signed, j.signature) {
+			return fmt.Errorf("invalid JWT signature")
+		}
+		return nil
+	}
+
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	hash, ok := jwtHashes[alg[2:]]
+	if !ok {
+		return fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(j.signed)
+	digest := h.Sum(nil)
+
+	var err error
+	switch alg[:2] {
+	case "RS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key is not an RSA key for %s", alg)
+		}
+		err = rsa.VerifyPKCS1v15(pub, hash, digest, j.signature)
+	case "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key is not an RSA key for %s", alg)
+		}
+		err = rsa.VerifyPSS(pub, hash, digest, j.signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key is not an EC key for %s", alg)
+		}
+		if pub.Curve != jwtCurves[alg] {
+			return fmt.Errorf("key curve %s does not match %s", pub.Curve.Params().Name, alg)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(j.signature) != 2*size {
+			return fmt.Errorf("invalid JWT signature")
+		}
+		r := new(big.Int).SetBytes(j.signature[:size])
+		s := new(big.Int).SetBytes(j.signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			err = fmt.Errorf("invalid JWT signature")
+		}
+	default:
+		return fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid JWT signature")
+	}
+	return nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// publicKey is a verification key from a JWKS
+type publicKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+// parseJWKS returns the signing keys of a JWKS, keys of unknown types and encryption keys are skipped
+func parseJWKS(data []byte) ([]publicKey, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	var result []publicKey
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q in JWKS: %w", jwk.Kid, err)
+		}
+		if key == nil {
+			continue
+		}
+		result = append(result, publicKey{kid: jwk.Kid, alg: jwk.Alg, key: key})
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("JWKS has no signing keys")
+	}
+	return result, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64BigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64BigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64BigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64BigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, nil
+}
+
+func base64BigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing key parameter")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
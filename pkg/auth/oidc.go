@@ -0,0 +1,333 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+const (
+	defaultJWKSRefresh = time.Hour
+	// an unknown kid triggers a refresh, at most this often, so rotated keys are picked up before the next refresh
+	minJWKSRefresh = 10 * time.Second
+	// allowed clock skew between the issuer and brent
+	oidcLeeway = time.Minute
+)
+
+// OIDCOptions configures an authenticator that validates OIDC ID tokens locally
+type OIDCOptions struct {
+	// IssuerURL must match the iss claim
+	IssuerURL string
+	// Audiences one of which must be in the aud claim, usually the client ID
+	Audiences []string
+	// JWKSFile or JWKSURL is where the signing keys are loaded from
+	JWKSFile string
+	JWKSURL  string
+	// JWKSRefresh is how often the keys are loaded again, an hour by default
+	JWKSRefresh time.Duration
+
+	// UsernameClaim is the claim of the username, sub by default. If it is email the email_verified claim must not
+	// be false.
+	UsernameClaim  string
+	UsernamePrefix string
+	// GroupsClaim is the claim of the groups, a string or a list of strings
+	GroupsClaim  string
+	GroupsPrefix string
+	// ExtraClaims maps extra keys to the claims they are read from
+	ExtraClaims map[string]string
+}
+
+type oidcAuthenticator struct {
+	options OIDCOptions
+	keys    *jwks
+	now     func() time.Time
+}
+
+// NewOIDCAuthenticator returns an authenticator for bearer tokens issued by the configured issuer. The signing keys
+// are loaded before it returns and then refreshed in the background until ctx is done. Tokens that are not JWTs or
+// that are from another issuer are not authenticated, so another authenticator can try them.
+func NewOIDCAuthenticator(ctx context.Context, options OIDCOptions) (Authenticator, error) {
+	if options.IssuerURL == "" {
+		return nil, fmt.Errorf("OIDC issuer URL is required")
+	}
+	if len(options.Audiences) == 0 {
+		return nil, fmt.Errorf("OIDC audience is required")
+	}
+	if (options.JWKSFile == "") == (options.JWKSURL == "") {
+		return nil, fmt.Errorf("exactly one of OIDC JWKS file and JWKS URL is required")
+	}
+	if options.UsernameClaim == "" {
+		options.UsernameClaim = "sub"
+	}
+	if options.JWKSRefresh <= 0 {
+		options.JWKSRefresh = defaultJWKSRefresh
+	}
+
+	keys := &jwks{
+		file: options.JWKSFile,
+		url:  options.JWKSURL,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+	if err := keys.refresh(ctx); err != nil {
+		return nil, err
+	}
+	go keys.run(ctx, options.JWKSRefresh)
+
+	return &oidcAuthenticator{
+		options: options,
+		keys:    keys,
+		now:     time.Now,
+	}, nil
+}
+
+func (o *oidcAuthenticator) Authenticate(req *http.Request) (user.Info, bool, error) {
+	token := req.Header.Get("Authorization")
+	if !strings.HasPrefix(token, "Bearer ") {
+		return nil, false, nil
+	}
+
+	parsed, err := parseJWT(strings.TrimPrefix(token, "Bearer "))
+	if err == errNotJWT {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	if iss, _ := parsed.claims["iss"].(string); iss != o.options.IssuerURL {
+		return nil, false, nil
+	}
+
+	if err := o.keys.verify(req.Context(), parsed); err != nil {
+		return nil, false, err
+	}
+	if err := o.validate(parsed.claims); err != nil {
+		return nil, false, err
+	}
+
+	info, err := o.userInfo(parsed.claims)
+	if err != nil {
+		return nil, false, err
+	}
+	exp, _ := numericDate(parsed.claims["exp"])
+	return &ExpiringUser{Info: info, ExpiresAt: exp}, true, nil
+}
+
+func (o *oidcAuthenticator) validate(claims map[string]interface{}) error {
+	now := o.now()
+
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return fmt.Errorf("token has no expiry")
+	}
+	if now.After(exp.Add(oidcLeeway)) {
+		return fmt.Errorf("token expired at %s", exp.UTC().Format(time.RFC3339))
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(oidcLeeway).Before(nbf) {
+		return fmt.Errorf("token is not valid before %s", nbf.UTC().Format(time.RFC3339))
+	}
+
+	for _, aud := range stringsClaim(claims["aud"]) {
+		for _, want := range o.options.Audiences {
+			if aud == want {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("token audience does not match")
+}
+
+func (o *oidcAuthenticator) userInfo(claims map[string]interface{}) (user.Info, error) {
+	name, _ := claims[o.options.UsernameClaim].(string)
+	if name == "" {
+		return nil, fmt.Errorf("token has no %s claim", o.options.UsernameClaim)
+	}
+	if o.options.UsernameClaim == "email" {
+		if verified, ok := claims["email_verified"].(bool); ok && !verified {
+			return nil, fmt.Errorf("email %s is not verified", name)
+		}
+	}
+
+	info := &user.DefaultInfo{
+		Name: o.options.UsernamePrefix + name,
+	}
+	if sub, ok := claims["sub"].(string); ok {
+		info.UID = sub
+	}
+
+	if o.options.GroupsClaim != "" {
+		for _, group := range stringsClaim(claims[o.options.GroupsClaim]) {
+			info.Groups = append(info.Groups, o.options.GroupsPrefix+group)
+		}
+	}
+	info.Groups = append(info.Groups, user.AllAuthenticated)
+
+	for key, claim := range o.options.ExtraClaims {
+		values := stringsClaim(claims[claim])
+		if len(values) == 0 {
+			continue
+		}
+		if info.Extra == nil {
+			info.Extra = map[string][]string{}
+		}
+		info.Extra[key] = values
+	}
+
+	return info, nil
+}
+
+// stringsClaim returns a claim that is a string or a list as strings
+func stringsClaim(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			result = append(result, fmt.Sprint(item))
+		}
+		return result
+	}
+	return []string{fmt.Sprint(value)}
+}
+
+func numericDate(value interface{}) (time.Time, bool) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+// jwks holds the signing keys of the issuer, loaded from a file or a URL
+type jwks struct {
+	file   string
+	url    string
+	client *http.Client
+
+	// refreshes lets concurrent requests with an unknown key share one refresh
+	refreshes singleflight.Group
+
+	lock        sync.RWMutex
+	keys        []publicKey
+	lastAttempt time.Time
+}
+
+func (j *jwks) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.refresh(ctx); err != nil {
+				logrus.Errorf("failed to refresh OIDC JWKS, keeping the current keys: %v", err)
+			}
+		}
+	}
+}
+
+func (j *jwks) refresh(ctx context.Context) error {
+	// failed attempts count too, so requests with unknown keys can not make the issuer be fetched on every request
+	j.lock.Lock()
+	j.lastAttempt = time.Now()
+	j.lock.Unlock()
+
+	data, err := j.load(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.keys = keys
+	return nil
+}
+
+func (j *jwks) load(ctx context.Context) ([]byte, error) {
+	if j.file != "" {
+		return os.ReadFile(j.file)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get JWKS from %s: %s", j.url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+}
+
+func (j *jwks) verify(ctx context.Context, token *jwt) error {
+	candidates, stale := j.candidates(token.header)
+	if len(candidates) == 0 && stale {
+		// the issuer may have rotated its keys since the last refresh. The refresh is shared with other requests
+		// so it must not be canceled with this one, the client has a timeout.
+		_, err, _ := j.refreshes.Do("", func() (interface{}, error) {
+			if _, stale := j.candidates(token.header); !stale {
+				// refreshed by a request that just finished
+				return nil, nil
+			}
+			return nil, j.refresh(context.WithoutCancel(ctx))
+		})
+		if err != nil {
+			logrus.Errorf("failed to refresh OIDC JWKS: %v", err)
+		}
+		candidates, _ = j.candidates(token.header)
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no key found for token with kid %q", token.header.Kid)
+	}
+
+	var err error
+	for _, key := range candidates {
+		if err = token.verify(key.key); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (j *jwks) candidates(header jwtHeader) ([]publicKey, bool) {
+	j.lock.RLock()
+	defer j.lock.RUnlock()
+
+	var result []publicKey
+	for _, key := range j.keys {
+		if header.Kid != "" && key.kid != "" && key.kid != header.Kid {
+			continue
+		}
+		if key.alg != "" && key.alg != header.Alg {
+			continue
+		}
+		result = append(result, key)
+	}
+	return result, time.Since(j.lastAttempt) > minJWKSRefresh
+}
@@ -0,0 +1,332 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signJWT(t *testing.T, key crypto.Signer, alg, kid string, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + b64(sig)
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksData, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa",
+				"use": "sig",
+				"n":   b64(rsaKey.N.Bytes()),
+				"e":   b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec",
+				"crv": "P-256",
+				"x":   b64(ecKey.X.FillBytes(make([]byte, 32))),
+				"y":   b64(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+		},
+	})
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwksData, 0600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	authenticator, err := NewOIDCAuthenticator(ctx, OIDCOptions{
+		IssuerURL:     "https://issuer.example.com",
+		Audiences:     []string{"brent"},
+		JWKSFile:      jwksFile,
+		UsernameClaim: "email",
+		GroupsClaim:   "groups",
+		GroupsPrefix:  "oidc:",
+		ExtraClaims:   map[string]string{"example.com/tenant": "tenant"},
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	exp := time.Unix(now.Add(time.Hour).Unix(), 0)
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		result := map[string]interface{}{
+			"iss":            "https://issuer.example.com",
+			"aud":            []string{"other", "brent"},
+			"sub":            "1234",
+			"email":          "alice@example.com",
+			"email_verified": true,
+			"groups":         []string{"dev", "ops"},
+			"tenant":         "acme",
+			"exp":            exp.Unix(),
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(result, k)
+			} else {
+				result[k] = v
+			}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		want    user.Info
+		wantOK  bool
+		wantErr bool
+	}{
+		{
+			name:   "RSA",
+			token:  signJWT(t, rsaKey, "RS256", "rsa", claims(nil)),
+			wantOK: true,
+			want: &ExpiringUser{
+				Info: &user.DefaultInfo{
+					Name:   "alice@example.com",
+					UID:    "1234",
+					Groups: []string{"oidc:dev", "oidc:ops", user.AllAuthenticated},
+					Extra:  map[string][]string{"example.com/tenant": {"acme"}},
+				},
+				ExpiresAt: exp,
+			},
+		},
+		{
+			name:   "EC",
+			token:  signJWT(t, ecKey, "ES256", "ec", claims(map[string]interface{}{"groups": "dev", "tenant": nil})),
+			wantOK: true,
+			want: &ExpiringUser{
+				Info: &user.DefaultInfo{
+					Name:   "alice@example.com",
+					UID:    "1234",
+					Groups: []string{"oidc:dev", user.AllAuthenticated},
+				},
+				ExpiresAt: exp,
+			},
+		},
+		{
+			name:  "not a JWT",
+			token: "some-opaque-token",
+		},
+		{
+			name:  "other issuer",
+			token: signJWT(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"iss": "https://other.example.com"})),
+		},
+		{
+			name:    "wrong key",
+			token:   signJWT(t, otherKey, "RS256", "rsa", claims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "unknown kid",
+			token:   signJWT(t, otherKey, "RS256", "other", claims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			token:   signJWT(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()})),
+			wantErr: true,
+		},
+		{
+			name:    "no expiry",
+			token:   signJWT(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"exp": nil})),
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			token:   signJWT(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"aud": "other"})),
+			wantErr: true,
+		},
+		{
+			name:    "unverified email",
+			token:   signJWT(t, rsaKey, "RS256", "rsa", claims(map[string]interface{}{"email_verified": false})),
+			wantErr: true,
+		},
+		{
+			name:    "symmetric algorithm",
+			token:   signJWT(t, rsaKey, "HS256", "rsa", claims(nil)),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/pods", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			info, ok, err := authenticator.Authenticate(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.False(t, ok)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.want != nil {
+				assert.Equal(t, tt.want, info)
+			}
+		})
+	}
+}
+
+func TestOIDCJWKSURL(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"alg": "RS256",
+				"n":   b64(key.N.Bytes()),
+				"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	authenticator, err := NewOIDCAuthenticator(ctx, OIDCOptions{
+		IssuerURL: "https://issuer.example.com",
+		Audiences: []string{"brent"},
+		JWKSURL:   server.URL,
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/pods", nil)
+	req.Header.Set("Authorization", "Bearer "+signJWT(t, key, "RS256", "", map[string]interface{}{
+		"iss": "https://issuer.example.com",
+		"aud": "brent",
+		"sub": "bob",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}))
+
+	info, ok, err := authenticator.Authenticate(req)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "bob", info.GetName())
+}
+
+func TestJWKSRefreshUnknownKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var requests int64
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		if failing.Load() {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// slow enough for the concurrent requests to share the refresh
+		time.Sleep(50 * time.Millisecond)
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "known",
+				"n":   b64(key.N.Bytes()),
+				"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer server.Close()
+
+	keys := &jwks{url: server.URL, client: server.Client()}
+	require.NoError(t, keys.refresh(context.Background()))
+
+	token, err := parseJWT(signJWT(t, key, "RS256", "unknown", map[string]interface{}{"sub": "bob"}))
+	require.NoError(t, err)
+
+	// concurrent requests with an unknown key share one refresh
+	keys.lastAttempt = time.Time{}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Error(t, keys.verify(context.Background(), token))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(2), atomic.LoadInt64(&requests))
+
+	// a failed refresh also waits for the next one
+	failing.Store(true)
+	keys.lastAttempt = time.Time{}
+	assert.Error(t, keys.verify(context.Background(), token))
+	assert.Error(t, keys.verify(context.Background(), token))
+	assert.Equal(t, int64(3), atomic.LoadInt64(&requests))
+}
+
+func TestJWTCurve(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	sign := func(alg string, hash crypto.Hash) *jwt {
+		header, err := json.Marshal(map[string]string{"alg": alg})
+		require.NoError(t, err)
+		signed := b64(header) + "." + b64([]byte(`{"sub":"bob"}`))
+		h := hash.New()
+		h.Write([]byte(signed))
+		r, s, err := ecdsa.Sign(rand.Reader, key, h.Sum(nil))
+		require.NoError(t, err)
+		token, err := parseJWT(signed + "." + b64(append(r.FillBytes(make([]byte, 48)), s.FillBytes(make([]byte, 48))...)))
+		require.NoError(t, err)
+		return token
+	}
+
+	assert.NoError(t, sign("ES384", crypto.SHA384).verify(&key.PublicKey))
+	// the signature has the size of a P-384 signature but ES256 requires P-256
+	assert.Error(t, sign("ES256", crypto.SHA256).verify(&key.PublicKey))
+}
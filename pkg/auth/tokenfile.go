@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+type staticTokenAuthenticator struct {
+	// users by the hash of their token, so the map lookup does not leak token prefixes through timing
+	users map[[sha256.Size]byte]*user.DefaultInfo
+}
+
+// NewStaticTokenAuthenticator authenticates bearer tokens listed in a CSV file in the format of the Kubernetes token
+// file: token,user,uid,"group1,group2"
+func NewStaticTokenAuthenticator(file string) (Authenticator, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	users := map[[sha256.Size]byte]*user.DefaultInfo{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("token file %s line %d: expected token,user,uid[,groups]", file, line)
+		}
+
+		token := strings.TrimSpace(record[0])
+		if token == "" {
+			return nil, fmt.Errorf("token file %s line %d: empty token", file, line)
+		}
+		info := &user.DefaultInfo{
+			Name: strings.TrimSpace(record[1]),
+			UID:  strings.TrimSpace(record[2]),
+		}
+		if len(record) > 3 {
+			for _, group := range strings.Split(record[3], ",") {
+				if group = strings.TrimSpace(group); group != "" {
+					info.Groups = append(info.Groups, group)
+				}
+			}
+		}
+		info.Groups = append(info.Groups, user.AllAuthenticated)
+
+		key := sha256.Sum256([]byte(token))
+		if _, ok := users[key]; ok {
+			return nil, fmt.Errorf("token file %s line %d: duplicate token", file, line)
+		}
+		users[key] = info
+	}
+
+	return &staticTokenAuthenticator{
+		users: users,
+	}, nil
+}
+
+func (s *staticTokenAuthenticator) Authenticate(req *http.Request) (user.Info, bool, error) {
+	token := req.Header.Get("Authorization")
+	if !strings.HasPrefix(token, "Bearer ") {
+		return nil, false, nil
+	}
+
+	info, ok := s.users[sha256.Sum256([]byte(strings.TrimPrefix(token, "Bearer ")))]
+	if !ok {
+		return nil, false, nil
+	}
+	return info, true, nil
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// AnonymousPolicy is what happens to requests no authenticator recognizes
+type AnonymousPolicy string
+
+const (
+	// AnonymousDeny rejects the request with 401
+	AnonymousDeny AnonymousPolicy = "deny"
+	// AnonymousAllow serves the request as system:anonymous, which can only do what RBAC grants it
+	AnonymousAllow AnonymousPolicy = "allow"
+
+	AnonymousUser = "system:anonymous"
+)
+
+func ParseAnonymousPolicy(s string) (AnonymousPolicy, error) {
+	switch AnonymousPolicy(s) {
+	case "", AnonymousAllow:
+		return AnonymousAllow, nil
+	case AnonymousDeny:
+		return AnonymousDeny, nil
+	}
+	return "", fmt.Errorf("invalid anonymous policy %s, must be allow or deny", s)
+}
+
+var authenticationAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "brent_authentication_attempts_total",
+	Help: "Requests by the authenticator that decided them and the result: success, error, allowed or denied",
+}, []string{"authenticator", "result"})
+
+func init() {
+	prometheus.MustRegister(authenticationAttempts)
+}
+
+// NamedAuthenticator is an authenticator of a union, the name is what logs, metrics and AuthenticatedBy report
+type NamedAuthenticator struct {
+	Name string
+	Authenticator
+}
+
+// AuthenticationError is the error of an authenticator that rejected the credentials of a request
+type AuthenticationError struct {
+	Authenticator string
+	Err           error
+}
+
+func (e *AuthenticationError) Error() string {
+	return fmt.Sprintf("%s authentication failed: %v", e.Authenticator, e.Err)
+}
+
+func (e *AuthenticationError) Unwrap() error {
+	return e.Err
+}
+
+type authenticatorKey struct{}
+
+// AuthenticatedBy returns the name of the authenticator of a union that authenticated the request, or
+// "anonymous" if it was allowed without credentials
+func AuthenticatedBy(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(authenticatorKey{}).(string)
+	return name, ok
+}
+
+// NewUnionMiddleware authenticates requests with the first authenticator that recognizes them. Unlike ToMiddleware
+// failures are not turned into a synthetic user: if an authenticator returns an error and none succeeds the request
+// is rejected with 401. Requests no authenticator recognizes are handled by the anonymous policy.
+func NewUnionMiddleware(anonymous AnonymousPolicy, authenticators ...NamedAuthenticator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			info, name, err := authenticateUnion(req, authenticators)
+			switch {
+			case err != nil:
+				logrus.Debugf("rejecting %s %s: %v", req.Method, req.URL.Path, err)
+				unauthorized(rw, "authentication failed")
+				return
+			case info == nil && anonymous != AnonymousAllow:
+				authenticationAttempts.WithLabelValues("anonymous", "denied").Inc()
+				unauthorized(rw, "authentication required")
+				return
+			case info == nil:
+				authenticationAttempts.WithLabelValues("anonymous", "allowed").Inc()
+				info = &user.DefaultInfo{
+					Name:   AnonymousUser,
+					Groups: []string{user.AllUnauthenticated},
+				}
+				name = "anonymous"
+			}
+
+			ctx := request.WithUser(req.Context(), info)
+			ctx = context.WithValue(ctx, authenticatorKey{}, name)
+			next.ServeHTTP(rw, req.WithContext(ctx))
+		})
+	}
+}
+
+// authenticateUnion returns the user and the name of the first authenticator that authenticates the request. The
+// errors of authenticators that failed are only returned if none succeeds.
+func authenticateUnion(req *http.Request, authenticators []NamedAuthenticator) (user.Info, string, error) {
+	var errs []error
+	for _, authenticator := range authenticators {
+		info, ok, err := authenticator.Authenticate(req)
+		if err != nil {
+			authenticationAttempts.WithLabelValues(authenticator.Name, "error").Inc()
+			logrus.Debugf("%s authentication of %s %s failed: %v", authenticator.Name, req.Method, req.URL.Path, err)
+			errs = append(errs, &AuthenticationError{Authenticator: authenticator.Name, Err: err})
+			continue
+		}
+		if ok {
+			authenticationAttempts.WithLabelValues(authenticator.Name, "success").Inc()
+			return info, authenticator.Name, nil
+		}
+	}
+	return nil, "", errors.Join(errs...)
+}
+
+func unauthorized(rw http.ResponseWriter, message string) {
+	rw.Header().Set("WWW-Authenticate", "Bearer")
+	apierror.WriteError(rw, http.StatusUnauthorized, "Unauthorized", message)
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func tokenAuthenticator(token, name string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) (user.Info, bool, error) {
+		switch req.Header.Get("Authorization") {
+		case "Bearer " + token:
+			return &user.DefaultInfo{Name: name}, true, nil
+		case "Bearer bad-" + token:
+			return nil, false, errors.New("invalid token")
+		}
+		return nil, false, nil
+	})
+}
+
+func TestUnionMiddleware(t *testing.T) {
+	authenticators := []NamedAuthenticator{
+		{Name: "first", Authenticator: tokenAuthenticator("one", "alice")},
+		{Name: "second", Authenticator: tokenAuthenticator("two", "bob")},
+	}
+
+	tests := []struct {
+		name          string
+		anonymous     AnonymousPolicy
+		authorization string
+		wantCode      int
+		wantUser      string
+		wantBy        string
+	}{
+		{
+			name:          "first authenticator",
+			authorization: "Bearer one",
+			wantCode:      http.StatusOK,
+			wantUser:      "alice",
+			wantBy:        "first",
+		},
+		{
+			name:          "second authenticator",
+			authorization: "Bearer two",
+			wantCode:      http.StatusOK,
+			wantUser:      "bob",
+			wantBy:        "second",
+		},
+		{
+			name:          "error is rejected",
+			anonymous:     AnonymousAllow,
+			authorization: "Bearer bad-one",
+			wantCode:      http.StatusUnauthorized,
+		},
+		{
+			name:      "anonymous allowed",
+			anonymous: AnonymousAllow,
+			wantCode:  http.StatusOK,
+			wantUser:  AnonymousUser,
+			wantBy:    "anonymous",
+		},
+		{
+			name:          "anonymous denied",
+			anonymous:     AnonymousDeny,
+			authorization: "Bearer unknown",
+			wantCode:      http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUser, gotBy string
+			handler := NewUnionMiddleware(tt.anonymous, authenticators...)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				info, _ := request.UserFrom(req.Context())
+				gotUser = info.GetName()
+				gotBy, _ = AuthenticatedBy(req.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/pods", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantUser, gotUser)
+			assert.Equal(t, tt.wantBy, gotBy)
+		})
+	}
+}
+
+func TestAuthenticationError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/v1/pods", nil)
+	req.Header.Set("Authorization", "Bearer bad-one")
+
+	_, _, err := authenticateUnion(req, []NamedAuthenticator{
+		{Name: "first", Authenticator: tokenAuthenticator("one", "alice")},
+	})
+
+	var authErr *AuthenticationError
+	if assert.True(t, errors.As(err, &authErr)) {
+		assert.Equal(t, "first", authErr.Authenticator)
+	}
+}
+
+func TestStaticTokenAuthenticator(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tokens.csv")
+	require.NoError(t, os.WriteFile(file, []byte(`# token,user,uid,groups
+abc,alice,1,"dev,ops"
+def,bob,2
+`), 0600))
+
+	authenticator, err := NewStaticTokenAuthenticator(file)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/pods", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	info, ok, err := authenticator.Authenticate(req)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, &user.DefaultInfo{Name: "alice", UID: "1", Groups: []string{"dev", "ops", user.AllAuthenticated}}, info)
+
+	req.Header.Set("Authorization", "Bearer xyz")
+	_, ok, err = authenticator.Authenticate(req)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
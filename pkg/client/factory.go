@@ -111,6 +111,11 @@ func (p *Factory) K8sInterface(ctx *types2.APIRequest) (kubernetes.Interface, er
 	return kubernetes.NewForConfig(cfg)
 }
 
+// RESTConfig returns the config of the user's clients, for requests such as exec that need more than a clientset.
+func (p *Factory) RESTConfig(ctx *types2.APIRequest) (*rest.Config, error) {
+	return setupConfig(ctx, p.clientCfg, p.impersonate)
+}
+
 func (p *Factory) AdminK8sInterface() (kubernetes.Interface, error) {
 	return kubernetes.NewForConfig(p.clientCfg)
 }
@@ -57,6 +57,9 @@ func toError(apiError *apierror.APIError) types.APIObject {
 	if apiError.FieldName != "" {
 		e["fieldName"] = apiError.FieldName
 	}
+	if apiError.Details != nil {
+		e["details"] = apiError.Details
+	}
 
 	return types.APIObject{
 		Type:   "error",
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/acorn-io/brent/pkg/apierror"
@@ -9,11 +10,31 @@ import (
 	"github.com/acorn-io/schemer/validation"
 )
 
+// updateLinks are the links that can be written with PUT and PATCH, the status and scale subresources. Other links
+// such as log or exec are only read.
+var updateLinks = map[string]bool{
+	"status": true,
+	"scale":  true,
+}
+
 func UpdateHandler(apiOp *types.APIRequest) (types.APIObject, error) {
 	if err := apiOp.AccessControl.CanUpdate(apiOp, types.APIObject{}, apiOp.Schema); err != nil {
 		return types.APIObject{}, err
 	}
 
+	if apiOp.Link != "" {
+		handler, ok := apiOp.Schema.LinkHandlers[apiOp.Link]
+		if !ok {
+			return types.APIObject{}, apierror.NewAPIError(validation.NotFound, "no such link "+apiOp.Link)
+		}
+		if !updateLinks[apiOp.Link] {
+			return types.APIObject{}, apierror.NewAPIError(validation.MethodNotAllowed,
+				fmt.Sprintf("Method %s not supported for %s", apiOp.Method, apiOp.Link))
+		}
+		handler.ServeHTTP(apiOp.Response, apiOp.Request)
+		return types.APIObject{}, validation.ErrComplete
+	}
+
 	var (
 		data types.APIObject
 		err  error
@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/gorilla/websocket"
+	"golang.org/x/time/rate"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+const (
+	RateLimitRead  = "read"
+	RateLimitWrite = "write"
+	RateLimitWatch = "watch"
+
+	// idle buckets are full again long before this and can be dropped
+	rateLimitIdle = 10 * time.Minute
+)
+
+// RateLimit is the rate and burst of one class of requests. A zero QPS does not limit the class.
+type RateLimit struct {
+	QPS   float64
+	Burst int
+}
+
+// ParseRateLimit parses a rate in requests per second, such as 0.5 or 20, an empty rate does not limit
+func ParseRateLimit(qps string, burst int) (RateLimit, error) {
+	if qps == "" {
+		return RateLimit{Burst: burst}, nil
+	}
+	f, err := strconv.ParseFloat(qps, 64)
+	if err != nil || f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %s, must be a number of requests per second", qps)
+	}
+	return RateLimit{QPS: f, Burst: burst}, nil
+}
+
+// RateLimitOptions configures the token buckets each user gets for reads, writes and watch opens.
+type RateLimitOptions struct {
+	Read  RateLimit
+	Write RateLimit
+	Watch RateLimit
+	// ExemptGroups are never limited, such as system:masters
+	ExemptGroups []string
+}
+
+func (o RateLimitOptions) limit(class string) RateLimit {
+	switch class {
+	case RateLimitWrite:
+		return o.Write
+	case RateLimitWatch:
+		return o.Watch
+	}
+	return o.Read
+}
+
+// Enabled returns true if any class of requests is limited
+func (o RateLimitOptions) Enabled() bool {
+	return o.Read.QPS > 0 || o.Write.QPS > 0 || o.Watch.QPS > 0
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type RateLimiter struct {
+	options RateLimitOptions
+	exempt  map[string]bool
+
+	lock      sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func NewRateLimiter(options RateLimitOptions) *RateLimiter {
+	exempt := map[string]bool{}
+	for _, group := range options.ExemptGroups {
+		exempt[group] = true
+	}
+	return &RateLimiter{
+		options: options,
+		exempt:  exempt,
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// Middleware rejects requests over the limit of the authenticated user with 429 and a Retry-After header. It must
+// run after authentication, requests without a user are not limited.
+func (r *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		info, ok := request.UserFrom(req.Context())
+		if !ok {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		if retryAfter, ok := r.Allow(info, requestClass(req)); !ok {
+			tooManyRequests(rw, retryAfter)
+			return
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// Allow takes a token from the user's bucket for the class of request. If the bucket is empty it returns false and
+// how long until a token is available.
+func (r *RateLimiter) Allow(info user.Info, class string) (time.Duration, bool) {
+	limit := r.options.limit(class)
+	if limit.QPS <= 0 || r.isExempt(info) {
+		return 0, true
+	}
+
+	now := r.now()
+	reservation := r.bucket(class+"/"+userKey(info), limit, now).ReserveN(now, 1)
+	if !reservation.OK() {
+		return time.Second, false
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return delay, false
+	}
+	return 0, true
+}
+
+func (r *RateLimiter) isExempt(info user.Info) bool {
+	for _, group := range info.GetGroups() {
+		if r.exempt[group] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RateLimiter) bucket(key string, limit RateLimit, now time.Time) *rate.Limiter {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if now.Sub(r.lastPrune) > rateLimitIdle {
+		for k, b := range r.buckets {
+			if now.Sub(b.lastSeen) > rateLimitIdle {
+				delete(r.buckets, k)
+			}
+		}
+		r.lastPrune = now
+	}
+
+	b, ok := r.buckets[key]
+	if !ok {
+		burst := limit.Burst
+		if burst <= 0 {
+			burst = int(math.Ceil(limit.QPS))
+		}
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.QPS), burst)}
+		r.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b.limiter
+}
+
+// userKey identifies the user by name and groups, the same name with different groups is limited separately
+func userKey(info user.Info) string {
+	groups := append([]string{}, info.GetGroups()...)
+	sort.Strings(groups)
+	return info.GetName() + "/" + strings.Join(groups, ",")
+}
+
+func requestClass(req *http.Request) string {
+	if websocket.IsWebSocketUpgrade(req) ||
+		strings.Contains(req.Header.Get("Accept"), "text/event-stream") {
+		return RateLimitWatch
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		if watch, _ := strconv.ParseBool(req.URL.Query().Get("watch")); watch {
+			return RateLimitWatch
+		}
+		return RateLimitRead
+	}
+	return RateLimitWrite
+}
+
+func tooManyRequests(rw http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	rw.Header().Set("Retry-After", strconv.Itoa(seconds))
+	apierror.WriteError(rw, http.StatusTooManyRequests, "TooManyRequests",
+		"rate limit exceeded, retry after "+strconv.Itoa(seconds)+"s")
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(RateLimitOptions{
+		Read:         RateLimit{QPS: 1, Burst: 2},
+		Write:        RateLimit{QPS: 0.5, Burst: 1},
+		ExemptGroups: []string{"system:masters"},
+	})
+	limiter.now = func() time.Time { return now }
+
+	alice := &user.DefaultInfo{Name: "alice", Groups: []string{"dev"}}
+	bob := &user.DefaultInfo{Name: "bob", Groups: []string{"dev"}}
+	admin := &user.DefaultInfo{Name: "admin", Groups: []string{"system:masters"}}
+
+	_, ok := limiter.Allow(alice, RateLimitRead)
+	assert.True(t, ok)
+	_, ok = limiter.Allow(alice, RateLimitRead)
+	assert.True(t, ok)
+	retryAfter, ok := limiter.Allow(alice, RateLimitRead)
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, retryAfter)
+
+	// buckets are per user and per class
+	_, ok = limiter.Allow(bob, RateLimitRead)
+	assert.True(t, ok)
+	_, ok = limiter.Allow(alice, RateLimitWrite)
+	assert.True(t, ok)
+	retryAfter, ok = limiter.Allow(alice, RateLimitWrite)
+	assert.False(t, ok)
+	assert.Equal(t, 2*time.Second, retryAfter)
+
+	// unlimited classes and exempt groups
+	for i := 0; i < 10; i++ {
+		_, ok = limiter.Allow(alice, RateLimitWatch)
+		assert.True(t, ok)
+		_, ok = limiter.Allow(admin, RateLimitWrite)
+		assert.True(t, ok)
+	}
+
+	now = now.Add(time.Second)
+	_, ok = limiter.Allow(alice, RateLimitRead)
+	assert.True(t, ok)
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitOptions{
+		Write: RateLimit{QPS: 1, Burst: 1},
+	})
+	handler := limiter.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/configmaps", nil)
+		req = req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: "alice"}))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost).Code)
+	rec := serve(http.MethodPost)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet).Code)
+}
+
+func TestRequestClass(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		header http.Header
+		want   string
+	}{
+		{method: http.MethodGet, url: "/v1/pods", want: RateLimitRead},
+		{method: http.MethodGet, url: "/api/v1/pods?watch=true", want: RateLimitWatch},
+		{method: http.MethodGet, url: "/v1/subscribe", header: http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}}, want: RateLimitWatch},
+		{method: http.MethodGet, url: "/v1/subscribe?resourceType=pod", header: http.Header{"Accept": {"text/event-stream"}}, want: RateLimitWatch},
+		{method: http.MethodDelete, url: "/v1/pods/default/web", want: RateLimitWrite},
+		{method: http.MethodPatch, url: "/v1/pods/default/web", want: RateLimitWrite},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.url, nil)
+		for k, v := range tt.header {
+			req.Header[k] = v
+		}
+		assert.Equal(t, tt.want, requestClass(req), tt.url)
+	}
+}
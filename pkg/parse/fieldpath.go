@@ -0,0 +1,67 @@
+package parse
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FieldPath splits a path such as metadata.labels[app.kubernetes.io/name] or metadata.fields[2] into its keys.
+// Brackets are used for keys that contain dots and for slice indexes.
+func FieldPath(path string) []string {
+	var (
+		result  []string
+		current strings.Builder
+		inKey   bool
+	)
+
+	flush := func() {
+		if current.Len() > 0 {
+			result = append(result, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, c := range path {
+		switch {
+		case inKey && c == ']':
+			result = append(result, current.String())
+			current.Reset()
+			inKey = false
+		case inKey:
+			current.WriteRune(c)
+		case c == '.':
+			flush()
+		case c == '[':
+			flush()
+			inKey = true
+		default:
+			current.WriteRune(c)
+		}
+	}
+	flush()
+
+	return result
+}
+
+// GetField walks maps and slices of obj following path. Numeric keys index into slices.
+func GetField(obj interface{}, path []string) (interface{}, bool) {
+	for _, key := range path {
+		switch v := obj.(type) {
+		case map[string]interface{}:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			obj = next
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			obj = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return obj, true
+}
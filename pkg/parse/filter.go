@@ -0,0 +1,199 @@
+package parse
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/data/convert"
+	"github.com/acorn-io/schemer/validation"
+)
+
+type operator struct {
+	op       string
+	modifier types.ModifierType
+}
+
+var operators = []operator{
+	// two character operators must be checked first
+	{"!=", types.ModifierNE},
+	{">=", types.ModifierGTE},
+	{"<=", types.ModifierLTE},
+	{"=", types.ModifierEQ},
+	{">", types.ModifierGT},
+	{"<", types.ModifierLT},
+}
+
+type Filter struct {
+	types.Condition
+	Field []string
+}
+
+// Filters parses the filter query parameters. Each filter is one of
+//
+//	field=value, field!=value, field>value, field>=value, field<value, field<=value
+//	field in (a,b), field notin (a,b)
+//	field (field is set), !field (field is not set)
+//
+// All filters must match for an object to be included.
+func Filters(query url.Values) ([]Filter, error) {
+	var result []Filter
+	for _, expr := range query["filter"] {
+		filter, err := parseFilter(strings.TrimSpace(expr))
+		if err != nil {
+			return nil, apierror.NewAPIError(validation.InvalidFormat, fmt.Sprintf("invalid filter %q: %v", expr, err))
+		}
+		result = append(result, filter)
+	}
+	return result, nil
+}
+
+func parseFilter(expr string) (Filter, error) {
+	if expr == "" {
+		return Filter{}, fmt.Errorf("empty expression")
+	}
+
+	for _, set := range []operator{
+		{" notin ", types.ModifierNotIn},
+		{" in ", types.ModifierIn},
+	} {
+		field, values, ok := strings.Cut(expr, set.op)
+		if !ok {
+			continue
+		}
+		values = strings.TrimSpace(values)
+		if !strings.HasPrefix(values, "(") || !strings.HasSuffix(values, ")") {
+			return Filter{}, fmt.Errorf("values must be enclosed in parentheses")
+		}
+		var list []interface{}
+		for _, v := range strings.Split(values[1:len(values)-1], ",") {
+			list = append(list, strings.TrimSpace(v))
+		}
+		return newFilter(field, set.modifier, list)
+	}
+
+	if i, op := findOperator(expr); i >= 0 {
+		return newFilter(expr[:i], op.modifier, strings.TrimSpace(expr[i+len(op.op):]))
+	}
+
+	if strings.HasPrefix(expr, "!") {
+		return newFilter(expr[1:], types.ModifierNull, nil)
+	}
+	return newFilter(expr, types.ModifierNotNull, nil)
+}
+
+func findOperator(expr string) (int, operator) {
+	depth := 0
+	for i := range expr {
+		switch expr[i] {
+		case '[':
+			depth++
+			continue
+		case ']':
+			depth--
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+		for _, op := range operators {
+			if strings.HasPrefix(expr[i:], op.op) {
+				return i, op
+			}
+		}
+	}
+	return -1, operator{}
+}
+
+func newFilter(field string, modifier types.ModifierType, value interface{}) (Filter, error) {
+	path := FieldPath(strings.TrimSpace(field))
+	if len(path) == 0 {
+		return Filter{}, fmt.Errorf("missing field")
+	}
+	return Filter{
+		Condition: types.Condition{
+			Modifier: modifier,
+			Value:    value,
+		},
+		Field: path,
+	}, nil
+}
+
+// Matches reports whether the object satisfies the filter.
+func (f Filter) Matches(obj map[string]interface{}) bool {
+	val, ok := GetField(obj, f.Field)
+	if ok && val == nil {
+		ok = false
+	}
+
+	switch f.Modifier {
+	case types.ModifierNull:
+		return !ok
+	case types.ModifierNotNull:
+		return ok
+	case types.ModifierNE:
+		return !ok || compareValues(val, f.Value) != 0
+	case types.ModifierNotIn:
+		return !ok || !in(val, f.Value)
+	}
+
+	if !ok {
+		return false
+	}
+
+	switch f.Modifier {
+	case types.ModifierEQ:
+		return compareValues(val, f.Value) == 0
+	case types.ModifierIn:
+		return in(val, f.Value)
+	case types.ModifierGT:
+		return compareValues(val, f.Value) > 0
+	case types.ModifierGTE:
+		return compareValues(val, f.Value) >= 0
+	case types.ModifierLT:
+		return compareValues(val, f.Value) < 0
+	case types.ModifierLTE:
+		return compareValues(val, f.Value) <= 0
+	}
+	return false
+}
+
+// MatchesAll reports whether the object satisfies every filter.
+func MatchesAll(filters []Filter, obj map[string]interface{}) bool {
+	for _, filter := range filters {
+		if !filter.Matches(obj) {
+			return false
+		}
+	}
+	return true
+}
+
+func in(val interface{}, values interface{}) bool {
+	for _, v := range convert.ToInterfaceSlice(values) {
+		if compareValues(val, v) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// compareValues compares numerically when both values are numbers and as strings otherwise.
+func compareValues(left, right interface{}) int {
+	leftStr, rightStr := convert.ToString(left), convert.ToString(right)
+	leftNum, leftErr := strconv.ParseFloat(leftStr, 64)
+	rightNum, rightErr := strconv.ParseFloat(rightStr, 64)
+	if leftErr == nil && rightErr == nil {
+		switch {
+		case leftNum < rightNum:
+			return -1
+		case leftNum > rightNum:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(leftStr, rightStr)
+}
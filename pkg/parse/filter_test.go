@@ -0,0 +1,118 @@
+package parse
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilters(t *testing.T) {
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "web-1",
+			"labels": map[string]interface{}{
+				"app":                    "web",
+				"app.kubernetes.io/name": "frontend",
+			},
+			"fields": []interface{}{"web-1", "1/1", "Running", int64(0)},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		filters []string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "equal",
+			filters: []string{"metadata.labels.app=web"},
+			want:    true,
+		},
+		{
+			name:    "equal mismatch",
+			filters: []string{"metadata.labels.app=db"},
+			want:    false,
+		},
+		{
+			name:    "not equal on missing field",
+			filters: []string{"metadata.labels.tier!=db"},
+			want:    true,
+		},
+		{
+			name:    "numeric greater than",
+			filters: []string{"spec.replicas>1"},
+			want:    true,
+		},
+		{
+			name:    "numeric comparison is not lexical",
+			filters: []string{"spec.replicas<10"},
+			want:    true,
+		},
+		{
+			name:    "greater or equal",
+			filters: []string{"spec.replicas>=3"},
+			want:    true,
+		},
+		{
+			name:    "bracketed key with dots",
+			filters: []string{"metadata.labels[app.kubernetes.io/name]=frontend"},
+			want:    true,
+		},
+		{
+			name:    "table field index",
+			filters: []string{"metadata.fields[2]=Running"},
+			want:    true,
+		},
+		{
+			name:    "in",
+			filters: []string{"metadata.labels.app in (db, web)"},
+			want:    true,
+		},
+		{
+			name:    "notin",
+			filters: []string{"metadata.labels.app notin (db, web)"},
+			want:    false,
+		},
+		{
+			name:    "not null",
+			filters: []string{"spec.replicas"},
+			want:    true,
+		},
+		{
+			name:    "null",
+			filters: []string{"!spec.paused"},
+			want:    true,
+		},
+		{
+			name:    "all filters must match",
+			filters: []string{"metadata.labels.app=web", "spec.replicas>5"},
+			want:    false,
+		},
+		{
+			name:    "in without parentheses",
+			filters: []string{"metadata.labels.app in db"},
+			wantErr: true,
+		},
+		{
+			name:    "missing field",
+			filters: []string{"=web"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := Filters(url.Values{"filter": tt.filters})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, MatchesAll(filters, obj))
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package parse
 
 import (
+	"mime"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/acorn-io/brent/pkg/types"
@@ -15,9 +17,11 @@ const (
 
 var (
 	allowedFormats = map[string]bool{
+		"csv":   true,
 		"html":  true,
 		"json":  true,
 		"jsonl": true,
+		"table": true,
 		"yaml":  true,
 	}
 )
@@ -145,6 +149,14 @@ func parseResponseFormat(req *http.Request) string {
 		return "jsonl"
 	}
 
+	if isCSV(req) {
+		return "csv"
+	}
+
+	if isTable(req) {
+		return "table"
+	}
+
 	return "json"
 }
 
@@ -156,6 +168,43 @@ func isJSONL(req *http.Request) bool {
 	return strings.Contains(req.Header.Get("Accept"), "application/jsonl")
 }
 
+func isCSV(req *http.Request) bool {
+	return prefers(req, "text/csv")
+}
+
+func isTable(req *http.Request) bool {
+	return prefers(req, "text/plain")
+}
+
+// prefers returns whether mediaType is the only media type of the Accept header with the highest quality,
+// parameters other than q are ignored. Headers such as "application/json, text/plain, */*" list text/plain next to
+// other types of the same quality and keep getting JSON.
+func prefers(req *http.Request, mediaType string) bool {
+	found := false
+	quality, others := 0.0, 0.0
+	for _, value := range req.Header.Values("Accept") {
+		for _, accept := range strings.Split(value, ",") {
+			accepted, params, err := mime.ParseMediaType(accept)
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					continue
+				}
+			}
+			if accepted == mediaType {
+				found = true
+				quality = max(quality, q)
+			} else {
+				others = max(others, q)
+			}
+		}
+	}
+	return found && quality > 0 && quality > others
+}
+
 func parseMethod(req *http.Request) string {
 	method := req.URL.Query().Get("_method")
 	if method == "" {
@@ -0,0 +1,39 @@
+package parse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefers(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{accept: "text/plain", want: true},
+		{accept: "TEXT/PLAIN; charset=utf-8", want: true},
+		{accept: "text/plain, application/json;q=0.5", want: true},
+		{accept: "application/json, text/plain, */*"},
+		{accept: "application/json, text/plain;q=0.5"},
+		{accept: "text/plain;q=0.5, application/json;q=0.5"},
+		{accept: "text/plain;q=0"},
+		{accept: "text/plain-foo"},
+		{accept: "application/text/plain"},
+		{accept: "*/*"},
+		{accept: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/pods", nil)
+			req.Header.Set("Accept", tt.accept)
+			assert.Equal(t, tt.want, prefers(req, "text/plain"))
+			if !tt.want {
+				assert.Equal(t, "json", parseResponseFormat(req))
+			}
+		})
+	}
+}
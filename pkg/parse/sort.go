@@ -0,0 +1,96 @@
+package parse
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/acorn-io/brent/pkg/types"
+)
+
+type Sort struct {
+	Field      []string
+	Descending bool
+}
+
+// Sorts parses the sort query parameter, a comma separated list of field paths. A field prefixed with - is
+// sorted in descending order. Later fields break ties of earlier fields.
+func Sorts(query url.Values) []Sort {
+	var result []Sort
+	for _, value := range query["sort"] {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			desc := strings.HasPrefix(field, "-")
+			path := FieldPath(strings.TrimPrefix(field, "-"))
+			if len(path) == 0 {
+				continue
+			}
+			result = append(result, Sort{
+				Field:      path,
+				Descending: desc,
+			})
+		}
+	}
+	return result
+}
+
+// SortObjects sorts objects in place. Objects missing a field sort after objects that have it.
+func SortObjects(sorts []Sort, objs []types.APIObject) {
+	if len(sorts) == 0 {
+		return
+	}
+
+	data := make([]map[string]interface{}, len(objs))
+	for i := range objs {
+		data[i] = objs[i].Data()
+	}
+
+	sort.Stable(&objectSorter{
+		sorts: sorts,
+		objs:  objs,
+		data:  data,
+	})
+}
+
+type objectSorter struct {
+	sorts []Sort
+	objs  []types.APIObject
+	data  []map[string]interface{}
+}
+
+func (o *objectSorter) Len() int {
+	return len(o.objs)
+}
+
+func (o *objectSorter) Swap(i, j int) {
+	o.objs[i], o.objs[j] = o.objs[j], o.objs[i]
+	o.data[i], o.data[j] = o.data[j], o.data[i]
+}
+
+func (o *objectSorter) Less(i, j int) bool {
+	for _, s := range o.sorts {
+		left, leftOK := GetField(o.data[i], s.Field)
+		right, rightOK := GetField(o.data[j], s.Field)
+		leftOK = leftOK && left != nil
+		rightOK = rightOK && right != nil
+
+		switch {
+		case !leftOK && !rightOK:
+			continue
+		case !leftOK:
+			return false
+		case !rightOK:
+			return true
+		}
+
+		c := compareValues(left, right)
+		if c == 0 {
+			continue
+		}
+		if s.Descending {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
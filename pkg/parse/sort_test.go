@@ -0,0 +1,73 @@
+package parse
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortObjects(t *testing.T) {
+	newObj := func(name, created string, fields ...interface{}) types.APIObject {
+		metadata := map[string]interface{}{
+			"name":   name,
+			"fields": fields,
+		}
+		if created != "" {
+			metadata["creationTimestamp"] = created
+		}
+		return types.APIObject{
+			ID: name,
+			Object: map[string]interface{}{
+				"metadata": metadata,
+			},
+		}
+	}
+
+	objs := []types.APIObject{
+		newObj("b", "2023-01-02T00:00:00Z", "b", int64(10)),
+		newObj("a", "2023-01-02T00:00:00Z", "a", int64(9)),
+		newObj("c", "2023-01-01T00:00:00Z", "c", int64(100)),
+		newObj("d", "", "d", int64(1)),
+	}
+
+	tests := []struct {
+		name string
+		sort string
+		want []string
+	}{
+		{
+			name: "single field",
+			sort: "metadata.name",
+			want: []string{"a", "b", "c", "d"},
+		},
+		{
+			name: "descending with tie breaker",
+			sort: "metadata.creationTimestamp,-metadata.name",
+			want: []string{"c", "b", "a", "d"},
+		},
+		{
+			name: "table column is numeric",
+			sort: "-metadata.fields[1]",
+			want: []string{"c", "b", "a", "d"},
+		},
+		{
+			name: "no sort keeps order",
+			sort: "",
+			want: []string{"b", "a", "c", "d"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := append([]types.APIObject{}, objs...)
+			SortObjects(Sorts(url.Values{"sort": []string{tt.sort}}), sorted)
+
+			var names []string
+			for _, obj := range sorted {
+				names = append(names, obj.ID)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
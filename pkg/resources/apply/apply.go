@@ -0,0 +1,279 @@
+package apply
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/attributes"
+	"github.com/acorn-io/brent/pkg/schema"
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	maxBodySize         = 10 << 20
+	defaultFieldManager = "brent"
+
+	StatusApplied = "applied"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+type SchemasGetter func(apiOp *types.APIRequest) *types.APISchemas
+
+// Apply is the bulk apply collection. A POST with a multi-document YAML or a List applies every object with
+// server-side apply through the store of its schema.
+type Apply struct {
+	DryRun  bool     `json:"dryRun,omitempty"`
+	Results []Result `json:"results,omitempty"`
+}
+
+type Result struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+}
+
+func Register(schemas *types.APISchemas, factory schema.Factory, getter SchemasGetter) {
+	schemas.MustImportAndCustomize(Apply{}, func(schema *types.APISchema) {
+		schema.CollectionMethods = []string{http.MethodPost}
+		schema.ResourceMethods = []string{}
+		schema.CreateHandler = func(apiOp *types.APIRequest) (types.APIObject, error) {
+			return handler(apiOp, factory, getter)
+		}
+	})
+}
+
+// Decode reads every object of a multi-document YAML or JSON body, the items of List kinds are returned in place
+// of the list.
+func Decode(reader io.Reader) ([]*unstructured.Unstructured, error) {
+	var result []*unstructured.Unstructured
+
+	decoder := yaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		obj := map[string]interface{}{}
+		if err := decoder.Decode(&obj); errors.Is(err, io.EOF) {
+			return result, nil
+		} else if err != nil {
+			return nil, apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("Failed to parse body: %v", err))
+		}
+		if len(obj) == 0 {
+			continue
+		}
+
+		u := &unstructured.Unstructured{Object: obj}
+		if !u.IsList() {
+			result = append(result, u)
+			continue
+		}
+
+		err := u.EachListItem(func(item runtime.Object) error {
+			result = append(result, item.(*unstructured.Unstructured))
+			return nil
+		})
+		if err != nil {
+			return nil, apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("Failed to parse list: %v", err))
+		}
+	}
+}
+
+func handler(apiOp *types.APIRequest, factory schema.Factory, getter SchemasGetter) (types.APIObject, error) {
+	objs, err := Decode(io.LimitReader(apiOp.Request.Body, maxBodySize))
+	if err != nil {
+		return types.APIObject{}, err
+	}
+
+	dryRun, err := parseDryRun(apiOp.Query["dryRun"])
+	if err != nil {
+		return types.APIObject{}, err
+	}
+
+	var (
+		schemas = getter(apiOp)
+		results []Result
+	)
+
+	// In atomic mode every object is validated with a dry run first and nothing is applied if any fails
+	if apiOp.Query.Get("atomic") == "true" && !dryRun {
+		results = applyAll(apiOp, factory, schemas, objs, true)
+		for _, result := range results {
+			if result.Status == StatusFailed {
+				return toAPIObject(true, skipApplied(results)), nil
+			}
+		}
+	}
+
+	results = applyAll(apiOp, factory, schemas, objs, dryRun)
+	return toAPIObject(dryRun, results), nil
+}
+
+// parseDryRun accepts the dryRun values of the apiserver, All, and true. Anything else is rejected instead of
+// silently applying or not applying.
+func parseDryRun(values []string) (bool, error) {
+	dryRun := false
+	for _, value := range values {
+		switch value {
+		case "":
+		case metav1.DryRunAll, "true":
+			dryRun = true
+		default:
+			return false, apierror.NewAPIError(validation.InvalidOption,
+				fmt.Sprintf("invalid dryRun %s, must be %s or true", value, metav1.DryRunAll))
+		}
+	}
+	return dryRun, nil
+}
+
+func toAPIObject(dryRun bool, results []Result) types.APIObject {
+	return types.APIObject{
+		Type: "apply",
+		Object: Apply{
+			DryRun:  dryRun,
+			Results: results,
+		},
+	}
+}
+
+// skipApplied marks the objects that passed validation as skipped because another object failed.
+func skipApplied(results []Result) []Result {
+	for i := range results {
+		if results[i].Status == StatusApplied {
+			results[i].Status = StatusSkipped
+			results[i].Message = "not applied because other objects failed validation"
+		}
+	}
+	return results
+}
+
+func applyAll(apiOp *types.APIRequest, factory schema.Factory, schemas *types.APISchemas, objs []*unstructured.Unstructured, dryRun bool) []Result {
+	results := make([]Result, 0, len(objs))
+	for _, obj := range objs {
+		result := Result{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+			Status:     StatusApplied,
+		}
+		if err := applyOne(apiOp, factory, schemas, obj, dryRun, &result); err != nil {
+			result.Status = StatusFailed
+			result.Message = errorMessage(err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func errorMessage(err error) string {
+	var apiErr *apierror.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Message
+	}
+	return err.Error()
+}
+
+func applyOne(apiOp *types.APIRequest, factory schema.Factory, schemas *types.APISchemas, obj *unstructured.Unstructured, dryRun bool, result *Result) error {
+	if obj.GetName() == "" {
+		return fmt.Errorf("metadata.name is required")
+	}
+
+	gvk := obj.GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" {
+		return fmt.Errorf("apiVersion and kind are required")
+	}
+
+	s := schemas.LookupSchema(factory.ByGVK(gvk))
+	if s == nil {
+		return fmt.Errorf("no resource type found for %s", gvk)
+	}
+	if s.Store == nil {
+		return fmt.Errorf("resource type %s does not support apply", s.ID)
+	}
+	result.Type = s.ID
+
+	if attributes.Namespaced(s) {
+		if obj.GetNamespace() == "" {
+			ns := apiOp.Query.Get("namespace")
+			if ns == "" {
+				ns = "default"
+			}
+			obj.SetNamespace(ns)
+			result.Namespace = ns
+		}
+	} else if obj.GetNamespace() != "" {
+		return fmt.Errorf("resource type %s is not namespaced", s.ID)
+	}
+
+	objOp, err := objectRequest(apiOp, s, obj, dryRun)
+	if err != nil {
+		return err
+	}
+
+	data := types.APIObject{Type: s.ID, Object: obj.Object}
+	if err := objOp.AccessControl.CanCreate(objOp, s); err != nil {
+		return err
+	}
+	if err := objOp.AccessControl.CanUpdate(objOp, data, s); err != nil {
+		return err
+	}
+
+	_, err = s.Store.Update(objOp, s, data, obj.GetName())
+	return err
+}
+
+// objectRequest builds the server-side apply PATCH request the store expects for obj.
+func objectRequest(apiOp *types.APIRequest, s *types.APISchema, obj *unstructured.Unstructured, dryRun bool) (*types.APIRequest, error) {
+	body, err := json.Marshal(obj.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("fieldManager", defaultFieldManager)
+	if fieldManager := apiOp.Query.Get("fieldManager"); fieldManager != "" {
+		query.Set("fieldManager", fieldManager)
+	}
+	if force := apiOp.Query.Get("force"); force != "" {
+		query.Set("force", force)
+	}
+	if dryRun {
+		query.Set("dryRun", "All")
+	}
+
+	u := *apiOp.Request.URL
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + obj.GetName()
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(apiOp.Context(), http.MethodPatch, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = apiOp.Request.Header.Clone()
+	req.Header.Set("Content-Type", string(apitypes.ApplyPatchType))
+
+	objOp := apiOp.Clone()
+	objOp.Request = req
+	objOp.Method = http.MethodPatch
+	objOp.Query = query
+	objOp.Schema = s
+	objOp.Type = s.ID
+	objOp.Namespace = obj.GetNamespace()
+	objOp.Name = obj.GetName()
+	return objOp, nil
+}
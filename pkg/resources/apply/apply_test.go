@@ -0,0 +1,124 @@
+package apply
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		names   []string
+		wantErr bool
+	}{
+		{
+			name: "multiple documents",
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: b
+`,
+			names: []string{"a", "b"},
+		},
+		{
+			name: "empty documents are skipped",
+			input: `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+---
+`,
+			names: []string{"a"},
+		},
+		{
+			name: "list items",
+			input: `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: b
+`,
+			names: []string{"a", "b"},
+		},
+		{
+			name:  "json",
+			input: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}}`,
+			names: []string{"a"},
+		},
+		{
+			name:    "invalid",
+			input:   "apiVersion: [",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs, err := Decode(strings.NewReader(tt.input))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			var names []string
+			for _, obj := range objs {
+				names = append(names, obj.GetName())
+			}
+			assert.Equal(t, tt.names, names)
+		})
+	}
+}
+
+func TestSkipApplied(t *testing.T) {
+	results := skipApplied([]Result{
+		{Name: "a", Status: StatusApplied},
+		{Name: "b", Status: StatusFailed, Message: "invalid"},
+	})
+	assert.Equal(t, StatusSkipped, results[0].Status)
+	assert.Equal(t, StatusFailed, results[1].Status)
+	assert.Equal(t, "invalid", results[1].Message)
+}
+
+func TestParseDryRun(t *testing.T) {
+	tests := []struct {
+		values  []string
+		want    bool
+		wantErr bool
+	}{
+		{values: nil},
+		{values: []string{""}},
+		{values: []string{"All"}, want: true},
+		{values: []string{"true"}, want: true},
+		{values: []string{"false"}, wantErr: true},
+		{values: []string{"0"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.values, ","), func(t *testing.T) {
+			dryRun, err := parseDryRun(tt.values)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, dryRun)
+		})
+	}
+}
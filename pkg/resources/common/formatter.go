@@ -1,15 +1,20 @@
 package common
 
 import (
+	"net/http"
 	"slices"
 	"strings"
 
 	"github.com/acorn-io/brent/pkg/accesscontrol"
 	"github.com/acorn-io/brent/pkg/attributes"
+	"github.com/acorn-io/brent/pkg/handlers"
 	"github.com/acorn-io/brent/pkg/schema"
+	"github.com/acorn-io/brent/pkg/schema/converter"
+	"github.com/acorn-io/brent/pkg/stores/partition"
 	"github.com/acorn-io/brent/pkg/stores/proxy"
 	"github.com/acorn-io/brent/pkg/summary"
 	"github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer"
 	"github.com/acorn-io/schemer/data"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,11 +23,85 @@ import (
 )
 
 func DefaultTemplate(clientGetter proxy.ClientGetter,
-	asl accesscontrol.AccessSetLookup) schema.Template {
+	asl accesscontrol.AccessSetLookup,
+	tokens *partition.ContinueTokens) schema.Template {
+	diff := proxy.DiffHandler(clientGetter)
+	bulkDelete := proxy.BulkDeleteHandler(clientGetter)
+	deleteBySelector := proxy.DeleteBySelector(clientGetter)
+	subresources := map[string]http.Handler{}
+	for _, subresource := range proxy.LinkedSubresources {
+		subresources[subresource] = proxy.SubresourceHandler(clientGetter, subresource)
+	}
 	return schema.Template{
-		Store:     proxy.NewProxyStore(clientGetter, asl),
+		Store:     proxy.NewProxyStore(clientGetter, asl, tokens),
 		Formatter: formatter,
+		Customize: func(apiSchema *types.APISchema) {
+			verbs := attributes.Verbs(apiSchema)
+			for _, subresource := range attributes.Subresources(apiSchema) {
+				handler, ok := subresources[subresource]
+				if !ok {
+					continue
+				}
+				if apiSchema.LinkHandlers == nil {
+					apiSchema.LinkHandlers = map[string]http.Handler{}
+				}
+				apiSchema.LinkHandlers[subresource] = handler
+			}
+
+			if slices.Contains(verbs, "update") {
+				resourceActions := map[string]schemas.Action{}
+				for name, action := range apiSchema.ResourceActions {
+					resourceActions[name] = action
+				}
+				resourceActions["diff"] = schemas.Action{}
+				apiSchema.ResourceActions = resourceActions
+
+				if apiSchema.ActionHandlers == nil {
+					apiSchema.ActionHandlers = map[string]http.Handler{}
+				}
+				apiSchema.ActionHandlers["diff"] = diff
+			}
+
+			if slices.Contains(verbs, "delete") {
+				collectionActions := map[string]schemas.Action{}
+				for name, action := range apiSchema.CollectionActions {
+					collectionActions[name] = action
+				}
+				collectionActions["bulkDelete"] = schemas.Action{}
+				apiSchema.CollectionActions = collectionActions
+
+				if apiSchema.ActionHandlers == nil {
+					apiSchema.ActionHandlers = map[string]http.Handler{}
+				}
+				apiSchema.ActionHandlers["bulkDelete"] = bulkDelete
+
+				// DELETE on the collection deletes by label selector
+				next := apiSchema.DeleteHandler
+				if next == nil {
+					next = handlers.DeleteHandler
+				}
+				apiSchema.DeleteHandler = func(apiOp *types.APIRequest) (types.APIObject, error) {
+					if apiOp.Name == "" {
+						return deleteBySelector(apiOp)
+					}
+					return next(apiOp)
+				}
+			}
+		},
+	}
+}
+
+// CachedTemplate is DefaultTemplate with List and ByID served from shared informers instead of the apiserver.
+func CachedTemplate(clientGetter proxy.ClientGetter,
+	asl accesscontrol.AccessSetLookup,
+	tokens *partition.ContinueTokens) schema.Template {
+	informers := proxy.NewInformers(clientGetter)
+	template := DefaultTemplate(clientGetter, asl, tokens)
+	template.StoreFactory = func(next types.Store) types.Store {
+		return proxy.NewCacheStore(next, clientGetter, informers, tokens)
 	}
+	template.StartSchema = informers.Start
+	return template
 }
 
 func selfLink(gvr schema2.GroupVersionResource, meta metav1.Object) (prefix string) {
@@ -86,6 +165,9 @@ func formatter(request *types.APIRequest, resource *types.RawResource) {
 			"transitioning": s.Transitioning,
 			"message":       strings.Join(s.Message, ":"),
 		}, "metadata", "state")
+		if len(s.Relationships) > 0 {
+			data.PutValue(unstr.Object, relationships(request.Schemas, s.Relationships), "metadata", "relationships")
+		}
 
 		summary.NormalizeConditions(unstr)
 
@@ -98,6 +180,44 @@ func formatter(request *types.APIRequest, resource *types.RawResource) {
 
 }
 
+// relationships converts the relationships of the summary to links between schemas. Owner references do not say
+// whether the owner is namespaced, so the namespace of the dependent is dropped for cluster scoped schemas.
+func relationships(schemas *types.APISchemas, rels []summary.Relationship) []interface{} {
+	result := make([]interface{}, 0, len(rels))
+	for _, rel := range rels {
+		gvk := schema2.FromAPIVersionAndKind(rel.APIVersion, rel.Kind)
+		toType := converter.GVKToSchemaID(gvk)
+		if rel.Namespace != "" && schemas != nil {
+			if toSchema := schemas.LookupSchema(toType); toSchema != nil && !attributes.Namespaced(toSchema) {
+				rel.Namespace = ""
+			}
+		}
+		m := map[string]interface{}{
+			"toType": toType,
+			"rel":    rel.Type,
+		}
+		if rel.Name != "" {
+			m["toId"] = rel.Name
+			if rel.Namespace != "" {
+				m["toId"] = rel.Namespace + "/" + rel.Name
+			}
+		} else if rel.Namespace != "" {
+			m["toNamespace"] = rel.Namespace
+		}
+		if rel.Selector != nil {
+			m["selector"] = metav1.FormatLabelSelector(rel.Selector)
+		}
+		if rel.Inbound {
+			m["inbound"] = true
+		}
+		if rel.ControlledBy {
+			m["controlledBy"] = true
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
 func includeFields(request *types.APIRequest, unstr *unstructured.Unstructured) {
 	if fields, ok := request.Query["include"]; ok {
 		newObj := map[string]interface{}{}
@@ -4,7 +4,10 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/acorn-io/brent/pkg/attributes"
+	"github.com/acorn-io/brent/pkg/summary"
 	"github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -541,3 +544,19 @@ func Test_excludeValues(t *testing.T) {
 		})
 	}
 }
+
+func Test_relationships(t *testing.T) {
+	node := &types.APISchema{Schema: &schemas.Schema{ID: "node"}}
+	replicaSet := &types.APISchema{Schema: &schemas.Schema{ID: "apps.replicaset"}}
+	attributes.SetNamespaced(replicaSet, true)
+	apiSchemas := types.EmptyAPISchemas().MustAddSchema(*node).MustAddSchema(*replicaSet)
+
+	got := relationships(apiSchemas, []summary.Relationship{
+		{Name: "node1", Namespace: "kube-system", Kind: "Node", APIVersion: "v1", Inbound: true, Type: "owner"},
+		{Name: "web", Namespace: "default", Kind: "ReplicaSet", APIVersion: "apps/v1", Inbound: true, ControlledBy: true, Type: "owner"},
+	})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"toType": "node", "toId": "node1", "rel": "owner", "inbound": true},
+		map[string]interface{}{"toType": "apps.replicaset", "toId": "default/web", "rel": "owner", "inbound": true, "controlledBy": true},
+	}, got)
+}
@@ -0,0 +1,219 @@
+package events
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/attributes"
+	"github.com/acorn-io/brent/pkg/schema"
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/data"
+	"github.com/acorn-io/schemer/data/convert"
+	"github.com/acorn-io/schemer/validation"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// eventSchemas are the schemas events are read from, in order of preference, with the field that references the
+// object the event is about
+var eventSchemas = []struct {
+	id    string
+	field string
+}{
+	{id: "event", field: "involvedObject"},
+	{id: "events.k8s.io.event", field: "regarding"},
+}
+
+// Template adds the events link to every namespaced schema. The link lists the events of the object, oldest first
+// with repeated events merged, through the store of the event schema so the user's access to events applies. New
+// events of the object are streamed by subscribing to the event type with the same fieldSelector from the revision
+// of the list.
+func Template() schema.Template {
+	handler := http.HandlerFunc(serveEvents)
+	return schema.Template{
+		Customize: func(apiSchema *types.APISchema) {
+			if !attributes.Namespaced(apiSchema) || attributes.Kind(apiSchema) == "" {
+				return
+			}
+			if apiSchema.LinkHandlers == nil {
+				apiSchema.LinkHandlers = map[string]http.Handler{}
+			}
+			apiSchema.LinkHandlers["events"] = handler
+		},
+	}
+}
+
+func serveEvents(rw http.ResponseWriter, req *http.Request) {
+	apiOp := types.GetAPIContext(req.Context())
+	eventOp, list, err := listEvents(apiOp)
+	if err != nil {
+		apiOp.WriteError(err)
+		return
+	}
+	eventOp.WriteResponseList(http.StatusOK, list)
+}
+
+func listEvents(apiOp *types.APIRequest) (*types.APIRequest, types.APIObjectList, error) {
+	obj, err := apiOp.Schema.Store.ByID(apiOp, apiOp.Schema, apiOp.Name)
+	if err != nil {
+		return nil, types.APIObjectList{}, err
+	}
+	m, err := meta.Accessor(obj.Object)
+	if err != nil {
+		return nil, types.APIObjectList{}, err
+	}
+
+	eventSchema, field := lookupEventSchema(apiOp.Schemas)
+	if eventSchema == nil || eventSchema.Store == nil {
+		return nil, types.APIObjectList{}, apierror.NewAPIError(validation.NotFound, "events are not available")
+	}
+	if err := apiOp.AccessControl.CanList(apiOp, eventSchema); err != nil {
+		return nil, types.APIObjectList{}, err
+	}
+
+	fieldSelector := fmt.Sprintf("%s.uid=%s", field, m.GetUID())
+	query := url.Values{}
+	query.Set("fieldSelector", fieldSelector)
+
+	u := *apiOp.Request.URL
+	u.RawQuery = query.Encode()
+	req := apiOp.Request.Clone(apiOp.Context())
+	req.URL = &u
+
+	eventOp := apiOp.Clone()
+	eventOp.Request = req
+	eventOp.Query = query
+	eventOp.Schema = eventSchema
+	eventOp.Type = eventSchema.ID
+	eventOp.Namespace = m.GetNamespace()
+	eventOp.Name = ""
+	eventOp.Link = ""
+
+	list, err := eventSchema.Store.List(eventOp, eventSchema)
+	if err != nil {
+		return nil, types.APIObjectList{}, err
+	}
+
+	list.Objects = Merge(list.Objects)
+	list.Continue = ""
+	return eventOp, list, nil
+}
+
+func lookupEventSchema(schemas *types.APISchemas) (*types.APISchema, string) {
+	for _, s := range eventSchemas {
+		if eventSchema := schemas.LookupSchema(s.id); eventSchema != nil {
+			return eventSchema, s.field
+		}
+	}
+	return nil, ""
+}
+
+type eventKey struct {
+	eventType string
+	reason    string
+	message   string
+	source    string
+}
+
+// Merge de-duplicates events with the same type, reason, message and source, keeping the most recent one with the
+// counts added up, and sorts them oldest first.
+func Merge(objs []types.APIObject) []types.APIObject {
+	var (
+		result []types.APIObject
+		counts = map[eventKey]int64{}
+		index  = map[eventKey]int{}
+	)
+
+	for _, obj := range objs {
+		event := obj.Data()
+		key := eventKey{
+			eventType: event.String("type"),
+			reason:    event.String("reason"),
+			message:   firstString(event, []string{"message"}, []string{"note"}),
+			source: firstString(event,
+				[]string{"source", "component"},
+				[]string{"reportingController"},
+				[]string{"reportingComponent"}),
+		}
+
+		count := eventCount(event)
+		counts[key] += count
+
+		if i, ok := index[key]; ok {
+			if lastSeen(event).After(lastSeen(result[i].Data())) {
+				result[i] = obj
+			}
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, obj)
+	}
+
+	for key, i := range index {
+		if counts[key] > eventCount(result[i].Data()) {
+			result[i] = withCount(result[i], counts[key])
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return lastSeen(result[i].Data()).Before(lastSeen(result[j].Data()))
+	})
+	return result
+}
+
+func withCount(obj types.APIObject, count int64) types.APIObject {
+	event := obj.Data()
+	event["count"] = count
+	obj.Object = &unstructured.Unstructured{Object: event}
+	return obj
+}
+
+func firstString(event data.Object, paths ...[]string) string {
+	for _, path := range paths {
+		if value := event.String(path...); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func eventCount(event data.Object) int64 {
+	count, _ := convert.ToNumber(event["count"])
+	if count == 0 {
+		count, _ = convert.ToNumber(data.GetValueN(event, "series", "count"))
+	}
+	if count == 0 {
+		count, _ = convert.ToNumber(data.GetValueN(event, "deprecatedCount"))
+	}
+	if count == 0 {
+		return 1
+	}
+	return count
+}
+
+// lastSeen is the latest of the times an event records
+func lastSeen(event data.Object) time.Time {
+	var result time.Time
+	for _, path := range [][]string{
+		{"lastTimestamp"},
+		{"deprecatedLastTimestamp"},
+		{"series", "lastObservedTime"},
+		{"eventTime"},
+		{"firstTimestamp"},
+		{"metadata", "creationTimestamp"},
+	} {
+		value := event.String(path...)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err == nil && t.After(result) {
+			result = t
+		}
+	}
+	return result
+}
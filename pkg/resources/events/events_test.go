@@ -0,0 +1,48 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func event(name, reason, message, lastTimestamp string, count int64) types.APIObject {
+	return types.APIObject{
+		Type: "event",
+		ID:   "default/" + name,
+		Object: &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata":      map[string]interface{}{"name": name, "namespace": "default"},
+			"type":          "Warning",
+			"reason":        reason,
+			"message":       message,
+			"source":        map[string]interface{}{"component": "kubelet"},
+			"lastTimestamp": lastTimestamp,
+			"count":         count,
+		}},
+	}
+}
+
+func TestMerge(t *testing.T) {
+	result := Merge([]types.APIObject{
+		event("backoff-1", "BackOff", "Back-off restarting failed container", "2024-01-01T10:05:00Z", 3),
+		event("pulled", "Pulled", "Successfully pulled image", "2024-01-01T10:00:00Z", 1),
+		event("backoff-2", "BackOff", "Back-off restarting failed container", "2024-01-01T10:10:00Z", 2),
+		event("failed", "Failed", "Error: ImagePullBackOff", "2024-01-01T10:07:00Z", 1),
+	})
+
+	var names []string
+	for _, obj := range result {
+		names = append(names, obj.Data().String("metadata", "name"))
+	}
+	assert.Equal(t, []string{"pulled", "failed", "backoff-2"}, names)
+	assert.Equal(t, int64(5), result[2].Data()["count"])
+	assert.Equal(t, int64(1), result[0].Data()["count"])
+}
+
+func TestEventCount(t *testing.T) {
+	assert.Equal(t, int64(1), eventCount(map[string]interface{}{}))
+	assert.Equal(t, int64(4), eventCount(map[string]interface{}{"series": map[string]interface{}{"count": int64(4)}}))
+	assert.Equal(t, int64(2), eventCount(map[string]interface{}{"deprecatedCount": int64(2)}))
+}
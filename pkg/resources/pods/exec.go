@@ -0,0 +1,365 @@
+package pods
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/stores/proxy"
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/validation"
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+const (
+	ExecStdin  = "stdin"
+	ExecStdout = "stdout"
+	ExecStderr = "stderr"
+	ExecResize = "resize"
+	ExecExit   = "exit"
+	ExecError  = "error"
+)
+
+// execMessage is a message of the exec websocket. Stdin and resize messages are sent by the client, output, exit and
+// error messages by the server.
+type execMessage struct {
+	Type    string `json:"type"`
+	Data    string `json:"data,omitempty"`
+	Width   uint16 `json:"width,omitempty"`
+	Height  uint16 `json:"height,omitempty"`
+	Code    *int   `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// execFraming encodes messages as websocket text messages
+type execFraming interface {
+	decode(data []byte) (execMessage, error)
+	encode(msg execMessage) ([]byte, error)
+}
+
+// jsonFraming sends every message as a JSON object, data is a UTF-8 string
+type jsonFraming struct{}
+
+func (jsonFraming) decode(data []byte) (msg execMessage, err error) {
+	err = json.Unmarshal(data, &msg)
+	return msg, err
+}
+
+func (jsonFraming) encode(msg execMessage) ([]byte, error) {
+	return json.Marshal(msg)
+}
+
+// base64Framing prefixes the base64 encoded data with its channel: 0 stdin, 1 stdout, 2 stderr, 3 exit or error
+// and 4 resize. Exit, error and resize data is the JSON message.
+type base64Framing struct{}
+
+var base64Channels = map[string]byte{
+	ExecStdin:  '0',
+	ExecStdout: '1',
+	ExecStderr: '2',
+	ExecExit:   '3',
+	ExecError:  '3',
+	ExecResize: '4',
+}
+
+func (base64Framing) decode(data []byte) (execMessage, error) {
+	if len(data) == 0 {
+		return execMessage{}, fmt.Errorf("empty message")
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(string(data[1:]))
+	if err != nil {
+		return execMessage{}, err
+	}
+
+	switch data[0] {
+	case base64Channels[ExecStdin]:
+		return execMessage{Type: ExecStdin, Data: string(payload)}, nil
+	case base64Channels[ExecResize]:
+		msg := execMessage{}
+		err := json.Unmarshal(payload, &msg)
+		msg.Type = ExecResize
+		return msg, err
+	default:
+		return execMessage{}, fmt.Errorf("invalid channel %q", data[0])
+	}
+}
+
+func (base64Framing) encode(msg execMessage) ([]byte, error) {
+	channel, ok := base64Channels[msg.Type]
+	if !ok {
+		return nil, fmt.Errorf("invalid message type %s", msg.Type)
+	}
+
+	payload := []byte(msg.Data)
+	if msg.Type != ExecStdout && msg.Type != ExecStderr {
+		var err error
+		if payload, err = json.Marshal(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	return append([]byte{channel}, base64.StdEncoding.EncodeToString(payload)...), nil
+}
+
+func framingFor(name string) (execFraming, error) {
+	switch name {
+	case "", "json":
+		return jsonFraming{}, nil
+	case "base64":
+		return base64Framing{}, nil
+	}
+	return nil, apierror.NewAPIError(validation.InvalidOption, fmt.Sprintf("invalid framing %s, must be json or base64", name))
+}
+
+// execHandler serves the exec and attach links of pods over a websocket. Input and output are framed as JSON or, with
+// framing=base64, as base64 with a channel prefix, and are streamed to the apiserver as the user.
+func execHandler(clientGetter proxy.ClientGetter, subresource string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		apiOp := types.GetAPIContext(req.Context())
+		if err := execStream(apiOp, clientGetter, subresource); err != nil {
+			apiOp.WriteError(proxy.TranslateError(err))
+		}
+	})
+}
+
+type execOptions struct {
+	container string
+	command   []string
+	stdin     bool
+	tty       bool
+}
+
+func parseExecOptions(apiOp *types.APIRequest, subresource string) (execOptions, error) {
+	query := apiOp.Request.URL.Query()
+	opts := execOptions{
+		container: query.Get("container"),
+		command:   query["command"],
+		stdin:     true,
+	}
+
+	var err error
+	if query.Get("stdin") != "" {
+		if opts.stdin, err = boolParam(query.Get("stdin")); err != nil {
+			return opts, apierror.NewAPIError(validation.InvalidFormat, "stdin: "+err.Error())
+		}
+	}
+	if opts.tty, err = boolParam(query.Get("tty")); err != nil {
+		return opts, apierror.NewAPIError(validation.InvalidFormat, "tty: "+err.Error())
+	}
+	if subresource == "exec" && len(opts.command) == 0 {
+		return opts, apierror.NewAPIError(validation.MissingRequired, "command is required")
+	}
+
+	return opts, nil
+}
+
+func execStream(apiOp *types.APIRequest, clientGetter proxy.ClientGetter, subresource string) error {
+	if !websocket.IsWebSocketUpgrade(apiOp.Request) {
+		return apierror.NewAPIError(validation.InvalidAction, subresource+" requires a websocket")
+	}
+
+	framing, err := framingFor(apiOp.Request.URL.Query().Get("framing"))
+	if err != nil {
+		return err
+	}
+
+	opts, err := parseExecOptions(apiOp, subresource)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := clientGetter.RESTConfig(apiOp)
+	if err != nil {
+		return err
+	}
+	k8s, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	req := k8s.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(apiOp.Namespace).
+		Name(apiOp.Name).
+		SubResource(subresource)
+	if subresource == "exec" {
+		req = req.VersionedParams(&corev1.PodExecOptions{
+			Container: opts.container,
+			Command:   opts.command,
+			Stdin:     opts.stdin,
+			Stdout:    true,
+			Stderr:    !opts.tty,
+			TTY:       opts.tty,
+		}, scheme.ParameterCodec)
+	} else {
+		req = req.VersionedParams(&corev1.PodAttachOptions{
+			Container: opts.container,
+			Stdin:     opts.stdin,
+			Stdout:    true,
+			Stderr:    !opts.tty,
+			TTY:       opts.tty,
+		}, scheme.ParameterCodec)
+	}
+
+	executor, err := remotecommand.NewSPDYExecutor(cfg, http.MethodPost, req.URL())
+	if err != nil {
+		return err
+	}
+
+	c, err := upgrader.Upgrade(apiOp.Response, apiOp.Request, nil)
+	if err != nil {
+		// the upgrader has already written the error response
+		return nil
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(apiOp.Context())
+	defer cancel()
+
+	session := newExecSession(c, framing)
+	go session.read(cancel)
+
+	streamOpts := remotecommand.StreamOptions{
+		Stdout: session.writer(ExecStdout),
+		Tty:    opts.tty,
+	}
+	if opts.stdin {
+		streamOpts.Stdin = session.stdin
+	}
+	if opts.tty {
+		streamOpts.TerminalSizeQueue = session
+	} else {
+		streamOpts.Stderr = session.writer(ExecStderr)
+	}
+
+	session.finish(executor.StreamWithContext(ctx, streamOpts))
+	return nil
+}
+
+type execSession struct {
+	conn    *websocket.Conn
+	framing execFraming
+	lock    sync.Mutex
+
+	stdin       *io.PipeReader
+	stdinWriter *io.PipeWriter
+	sizes       chan remotecommand.TerminalSize
+}
+
+func newExecSession(conn *websocket.Conn, framing execFraming) *execSession {
+	stdin, stdinWriter := io.Pipe()
+	return &execSession{
+		conn:        conn,
+		framing:     framing,
+		stdin:       stdin,
+		stdinWriter: stdinWriter,
+		sizes:       make(chan remotecommand.TerminalSize, 1),
+	}
+}
+
+// read forwards the client's messages until the websocket is closed
+func (e *execSession) read(cancel context.CancelFunc) {
+	defer cancel()
+	defer close(e.sizes)
+	defer e.stdinWriter.Close()
+
+	for {
+		_, data, err := e.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		msg, err := e.framing.decode(data)
+		if err != nil {
+			e.send(execMessage{Type: ExecError, Message: "invalid message: " + err.Error()})
+			continue
+		}
+
+		switch msg.Type {
+		case ExecStdin:
+			if _, err := e.stdinWriter.Write([]byte(msg.Data)); err != nil {
+				return
+			}
+		case ExecResize:
+			// only the latest size matters
+			select {
+			case <-e.sizes:
+			default:
+			}
+			e.sizes <- remotecommand.TerminalSize{Width: msg.Width, Height: msg.Height}
+		default:
+			e.send(execMessage{Type: ExecError, Message: "invalid message type " + msg.Type})
+		}
+	}
+}
+
+// Next implements remotecommand.TerminalSizeQueue
+func (e *execSession) Next() *remotecommand.TerminalSize {
+	size, ok := <-e.sizes
+	if !ok {
+		return nil
+	}
+	return &size
+}
+
+func (e *execSession) send(msg execMessage) error {
+	data, err := e.framing.encode(msg)
+	if err != nil {
+		return err
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (e *execSession) writer(stream string) io.Writer {
+	return execWriter{session: e, stream: stream}
+}
+
+// finish sends the exit code of the command, or the error that ended the stream, and closes the websocket
+func (e *execSession) finish(err error) {
+	// unblock the reader if it is still writing stdin
+	_ = e.stdin.Close()
+
+	code := 0
+	msg := execMessage{Type: ExecExit, Code: &code}
+
+	var exitErr exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitStatus()
+		msg.Message = exitErr.Error()
+	} else if err != nil {
+		msg = execMessage{Type: ExecError, Message: err.Error()}
+	}
+	_ = e.send(msg)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	_ = e.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+}
+
+type execWriter struct {
+	session *execSession
+	stream  string
+}
+
+func (w execWriter) Write(p []byte) (int, error) {
+	if err := w.session.send(execMessage{Type: w.stream, Data: string(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
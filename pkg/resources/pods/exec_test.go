@@ -0,0 +1,43 @@
+package pods
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase64Framing(t *testing.T) {
+	framing := base64Framing{}
+
+	msg, err := framing.decode([]byte("0bHMgLWwK"))
+	assert.NoError(t, err)
+	assert.Equal(t, execMessage{Type: ExecStdin, Data: "ls -l\n"}, msg)
+
+	msg, err = framing.decode([]byte("4eyJ3aWR0aCI6ODAsImhlaWdodCI6MjR9"))
+	assert.NoError(t, err)
+	assert.Equal(t, execMessage{Type: ExecResize, Width: 80, Height: 24}, msg)
+
+	_, err = framing.decode([]byte("1aGk="))
+	assert.Error(t, err)
+
+	data, err := framing.encode(execMessage{Type: ExecStdout, Data: "hi"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1aGk=", string(data))
+
+	code := 2
+	data, err = framing.encode(execMessage{Type: ExecExit, Code: &code})
+	assert.NoError(t, err)
+	assert.Equal(t, "3eyJ0eXBlIjoiZXhpdCIsImNvZGUiOjJ9", string(data))
+}
+
+func TestJSONFraming(t *testing.T) {
+	framing := jsonFraming{}
+
+	msg, err := framing.decode([]byte(`{"type":"resize","width":120,"height":40}`))
+	assert.NoError(t, err)
+	assert.Equal(t, execMessage{Type: ExecResize, Width: 120, Height: 40}, msg)
+
+	data, err := framing.encode(execMessage{Type: ExecStderr, Data: "oops\n"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"stderr","data":"oops\n"}`, string(data))
+}
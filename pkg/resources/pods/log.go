@@ -0,0 +1,168 @@
+package pods
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/stores/proxy"
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/validation"
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// upgrader upgrades the requests of the log and exec links
+var upgrader = websocket.Upgrader{
+	HandshakeTimeout:  60 * time.Second,
+	EnableCompression: true,
+}
+
+// logHandler serves the log link of pods. Logs are streamed as chunked text, or as one websocket text message per
+// line when the request is a websocket upgrade. The container, follow, tailLines, sinceSeconds and timestamps query
+// parameters are passed to the apiserver, which checks the user's access to pods/log.
+func logHandler(clientGetter proxy.ClientGetter) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		apiOp := types.GetAPIContext(req.Context())
+		if err := streamLogs(apiOp, clientGetter); err != nil {
+			apiOp.WriteError(proxy.TranslateError(err))
+		}
+	})
+}
+
+func logOptions(apiOp *types.APIRequest) (*corev1.PodLogOptions, error) {
+	query := apiOp.Request.URL.Query()
+	opts := &corev1.PodLogOptions{
+		Container: query.Get("container"),
+	}
+
+	var err error
+	if opts.Follow, err = boolParam(query.Get("follow")); err != nil {
+		return nil, apierror.NewAPIError(validation.InvalidFormat, "follow: "+err.Error())
+	}
+	if opts.Timestamps, err = boolParam(query.Get("timestamps")); err != nil {
+		return nil, apierror.NewAPIError(validation.InvalidFormat, "timestamps: "+err.Error())
+	}
+	if opts.TailLines, err = int64Param(query.Get("tailLines")); err != nil {
+		return nil, apierror.NewAPIError(validation.InvalidFormat, "tailLines: "+err.Error())
+	}
+	if opts.SinceSeconds, err = int64Param(query.Get("sinceSeconds")); err != nil {
+		return nil, apierror.NewAPIError(validation.InvalidFormat, "sinceSeconds: "+err.Error())
+	}
+
+	return opts, nil
+}
+
+func boolParam(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func int64Param(value string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("must not be negative")
+	}
+	return &i, nil
+}
+
+func streamLogs(apiOp *types.APIRequest, clientGetter proxy.ClientGetter) error {
+	opts, err := logOptions(apiOp)
+	if err != nil {
+		return err
+	}
+
+	k8s, err := clientGetter.K8sInterface(apiOp)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(apiOp.Context())
+	defer cancel()
+
+	// open the stream before responding so errors such as a missing container are returned with their status
+	logs, err := k8s.CoreV1().Pods(apiOp.Namespace).GetLogs(apiOp.Name, opts).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+
+	if websocket.IsWebSocketUpgrade(apiOp.Request) {
+		return websocketLogs(ctx, cancel, apiOp, logs)
+	}
+	return textLogs(apiOp, logs)
+}
+
+func textLogs(apiOp *types.APIRequest, logs io.Reader) error {
+	apiOp.Response.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	apiOp.Response.Header().Set("X-Content-Type-Options", "nosniff")
+	apiOp.Response.WriteHeader(http.StatusOK)
+
+	flusher, _ := apiOp.Response.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := logs.Read(buf)
+		if n > 0 {
+			if _, err := apiOp.Response.Write(buf[:n]); err != nil {
+				return nil
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+func websocketLogs(ctx context.Context, cancel context.CancelFunc, apiOp *types.APIRequest, logs io.Reader) error {
+	c, err := upgrader.Upgrade(apiOp.Response, apiOp.Request, nil)
+	if err != nil {
+		// the upgrader has already written the error response
+		return nil
+	}
+	defer c.Close()
+
+	// the client never sends anything, reading only notices when it goes away
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := c.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err := c.WriteMessage(websocket.TextMessage, scanner.Bytes()); err != nil {
+			return nil
+		}
+	}
+
+	closeCode, reason := websocket.CloseNormalClosure, ""
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		closeCode, reason = websocket.CloseInternalServerErr, err.Error()
+		// control frames are limited to 125 bytes
+		if len(reason) > 120 {
+			reason = reason[:120]
+		}
+	}
+	_ = c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, reason), time.Now().Add(time.Second))
+	return nil
+}
@@ -0,0 +1,63 @@
+package pods
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestLogOptions(t *testing.T) {
+	int64Ptr := func(i int64) *int64 { return &i }
+
+	tests := []struct {
+		name    string
+		query   string
+		want    *corev1.PodLogOptions
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  &corev1.PodLogOptions{},
+		},
+		{
+			name:  "all options",
+			query: "container=web&follow=true&tailLines=100&sinceSeconds=60&timestamps=1",
+			want: &corev1.PodLogOptions{
+				Container:    "web",
+				Follow:       true,
+				TailLines:    int64Ptr(100),
+				SinceSeconds: int64Ptr(60),
+				Timestamps:   true,
+			},
+		},
+		{
+			name:    "invalid follow",
+			query:   "follow=maybe",
+			wantErr: true,
+		},
+		{
+			name:    "negative tailLines",
+			query:   "tailLines=-1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiOp := &types.APIRequest{
+				Request: httptest.NewRequest("GET", "/v1/pods/default/web/log?"+tt.query, nil),
+			}
+			opts, err := logOptions(apiOp)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, opts)
+		})
+	}
+}
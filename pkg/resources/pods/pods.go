@@ -0,0 +1,27 @@
+package pods
+
+import (
+	"net/http"
+
+	"github.com/acorn-io/brent/pkg/schema"
+	"github.com/acorn-io/brent/pkg/stores/proxy"
+	"github.com/acorn-io/brent/pkg/types"
+)
+
+// Template adds the links of the core Pod schema.
+func Template(clientGetter proxy.ClientGetter) schema.Template {
+	log := logHandler(clientGetter)
+	exec := execHandler(clientGetter, "exec")
+	attach := execHandler(clientGetter, "attach")
+	return schema.Template{
+		ID: "pod",
+		Customize: func(apiSchema *types.APISchema) {
+			if apiSchema.LinkHandlers == nil {
+				apiSchema.LinkHandlers = map[string]http.Handler{}
+			}
+			apiSchema.LinkHandlers["log"] = log
+			apiSchema.LinkHandlers["exec"] = exec
+			apiSchema.LinkHandlers["attach"] = attach
+		},
+	}
+}
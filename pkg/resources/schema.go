@@ -4,10 +4,14 @@ import (
 	"github.com/acorn-io/brent/pkg/accesscontrol"
 	"github.com/acorn-io/brent/pkg/client"
 	"github.com/acorn-io/brent/pkg/resources/apigroups"
+	"github.com/acorn-io/brent/pkg/resources/apply"
 	"github.com/acorn-io/brent/pkg/resources/common"
+	"github.com/acorn-io/brent/pkg/resources/events"
+	"github.com/acorn-io/brent/pkg/resources/pods"
 	"github.com/acorn-io/brent/pkg/schema"
 	brentschema "github.com/acorn-io/brent/pkg/schema"
 	"github.com/acorn-io/brent/pkg/stores/apiroot"
+	"github.com/acorn-io/brent/pkg/stores/partition"
 	"github.com/acorn-io/brent/pkg/subscribe"
 	types2 "github.com/acorn-io/brent/pkg/types"
 	"k8s.io/apiserver/pkg/endpoints/request"
@@ -15,8 +19,8 @@ import (
 )
 
 func DefaultSchemas(baseSchema *types2.APISchemas,
-	schemaFactory brentschema.Factory, serverVersion string) error {
-	subscribe.Register(baseSchema, func(apiOp *types2.APIRequest) *types2.APISchemas {
+	schemaFactory brentschema.Factory, serverVersion string, subscribeOptions subscribe.Options) error {
+	getter := func(apiOp *types2.APIRequest) *types2.APISchemas {
 		user, ok := request.UserFrom(apiOp.Context())
 		if ok {
 			schemas, err := schemaFactory.Schemas(user)
@@ -25,16 +29,26 @@ func DefaultSchemas(baseSchema *types2.APISchemas,
 			}
 		}
 		return apiOp.Schemas
-	}, serverVersion)
+	}
+	subscribe.Register(baseSchema, getter, serverVersion, subscribeOptions)
+	apply.Register(baseSchema, schemaFactory, getter)
 	apiroot.Register(baseSchema, []string{"v1"}, "proxy:/apis")
 	return nil
 }
 
 func DefaultSchemaTemplates(cf *client.Factory,
 	lookup accesscontrol.AccessSetLookup,
-	discovery discovery.DiscoveryInterface) []schema.Template {
+	discovery discovery.DiscoveryInterface,
+	cache bool,
+	tokens *partition.ContinueTokens) []schema.Template {
+	defaultTemplate := common.DefaultTemplate(cf, lookup, tokens)
+	if cache {
+		defaultTemplate = common.CachedTemplate(cf, lookup, tokens)
+	}
 	return []schema.Template{
-		common.DefaultTemplate(cf, lookup),
+		defaultTemplate,
 		apigroups.Template(discovery),
+		pods.Template(cf),
+		events.Template(),
 	}
 }
@@ -49,6 +49,9 @@ type Template struct {
 	Store        types2.Store
 	Start        func(ctx context.Context) error
 	StoreFactory func(types2.Store) types2.Store
+	// StartSchema is called for every schema the template applies to when the schema is added. The
+	// context is canceled when the schema is removed.
+	StartSchema func(ctx context.Context, schema *types2.APISchema) error
 }
 
 func NewCollection(ctx context.Context, baseSchema *types2.APISchemas, access accesscontrol.AccessSetLookup) *Collection {
@@ -114,30 +117,43 @@ func (c *Collection) Reset(schemas map[string]*types2.APISchema) {
 	c.lock.RUnlock()
 }
 
-func start(ctx context.Context, templates []*Template) error {
+func start(ctx context.Context, schema *types2.APISchema, templates []*Template) error {
 	for _, template := range templates {
-		if template.Start == nil {
-			continue
+		if template.Start != nil && template.ID == schema.ID {
+			if err := template.Start(ctx); err != nil {
+				return err
+			}
 		}
-		if err := template.Start(ctx); err != nil {
-			return err
+		if template.StartSchema != nil {
+			if err := template.StartSchema(ctx, schema); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
+func needsStart(schema *types2.APISchema, templates []*Template) bool {
+	for _, template := range templates {
+		if template.StartSchema != nil || (template.Start != nil && template.ID == schema.ID) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Collection) startStopTemplate(schemas map[string]*types2.APISchema) {
-	for id := range schemas {
+	for id, schema := range schemas {
 		if _, ok := c.running[id]; ok {
 			continue
 		}
-		templates := c.templates[id]
-		if len(templates) == 0 {
+		templates := c.templatesFor(schema)
+		if !needsStart(schema, templates) {
 			continue
 		}
 
 		subCtx, cancel := context.WithCancel(c.ctx)
-		if err := start(subCtx, templates); err != nil {
+		if err := start(subCtx, schema, templates); err != nil {
 			cancel()
 			logrus.Errorf("failed to start schema template: %s", id)
 			continue
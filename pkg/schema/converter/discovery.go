@@ -1,6 +1,7 @@
 package converter
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/acorn-io/baaah/pkg/merr"
@@ -64,6 +65,7 @@ func indexVersions(groups []*metav1.APIGroup) map[string]string {
 }
 
 func refresh(gv schema.GroupVersion, groupToPreferredVersion map[string]string, resources *metav1.APIResourceList, schemasMap map[string]*types.APISchema) error {
+	byResource := map[string]*types.APISchema{}
 	for _, resource := range resources.APIResources {
 		if strings.Contains(resource.Name, "/") {
 			continue
@@ -96,6 +98,18 @@ func refresh(gv schema.GroupVersion, groupToPreferredVersion map[string]string,
 		}
 
 		schemasMap[schema.ID] = schema
+		byResource[resource.Name] = schema
+	}
+
+	// subresources such as deployments/status and deployments/scale are recorded on the schema of their resource
+	for _, resource := range resources.APIResources {
+		name, subresource, ok := strings.Cut(resource.Name, "/")
+		if !ok {
+			continue
+		}
+		if schema := byResource[name]; schema != nil && !slices.Contains(attributes.Subresources(schema), subresource) {
+			attributes.SetSubresources(schema, append(attributes.Subresources(schema), subresource))
+		}
 	}
 
 	return nil
@@ -0,0 +1,32 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/acorn-io/brent/pkg/attributes"
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRefreshSubresources(t *testing.T) {
+	schemasMap := map[string]*types.APISchema{}
+	gv := schema.GroupVersion{Group: "apps", Version: "v1"}
+	resources := &metav1.APIResourceList{
+		GroupVersion: gv.String(),
+		APIResources: []metav1.APIResource{
+			{Name: "deployments/status", Kind: "Deployment"},
+			{Name: "deployments", Kind: "Deployment", Namespaced: true},
+			{Name: "deployments/scale", Kind: "Scale", Group: "autoscaling", Version: "v1"},
+			{Name: "controllerrevisions", Kind: "ControllerRevision", Namespaced: true},
+		},
+	}
+
+	assert.NoError(t, refresh(gv, map[string]string{}, resources, schemasMap))
+	assert.NoError(t, refresh(gv, map[string]string{}, resources, schemasMap))
+
+	assert.Len(t, schemasMap, 2)
+	assert.Equal(t, []string{"status", "scale"}, attributes.Subresources(schemasMap["apps.v1.deployment"]))
+	assert.Empty(t, attributes.Subresources(schemasMap["apps.v1.controllerrevision"]))
+}
@@ -107,6 +107,7 @@ func (c *Collection) schemasForSubject(access *accesscontrol.AccessSet) (*types2
 		}
 		if verbAccess.AnyVerb("delete") {
 			s.ResourceMethods = append(s.ResourceMethods, http.MethodDelete)
+			s.CollectionMethods = append(s.CollectionMethods, http.MethodDelete)
 		}
 		if verbAccess.AnyVerb("update") {
 			s.ResourceMethods = append(s.ResourceMethods, http.MethodPut)
@@ -143,32 +144,37 @@ func (c *Collection) applyTemplates(schema *types2.APISchema) {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 
-	templates := [][]*Template{
+	for _, t := range c.templatesFor(schema) {
+		if schema.Formatter == nil {
+			schema.Formatter = t.Formatter
+		} else if t.Formatter != nil {
+			schema.Formatter = types2.FormatterChain(t.Formatter, schema.Formatter)
+		}
+		if schema.Store == nil {
+			if t.StoreFactory == nil {
+				schema.Store = t.Store
+			} else {
+				schema.Store = t.StoreFactory(c.defaultStore())
+			}
+		}
+		if t.Customize != nil {
+			t.Customize(schema)
+		}
+	}
+}
+
+// templatesFor returns the templates that apply to the schema, most specific first. The caller must hold the lock.
+func (c *Collection) templatesFor(schema *types2.APISchema) (result []*Template) {
+	for _, templates := range [][]*Template{
 		c.templates[schema.ID],
 		c.templates[fmt.Sprintf("%s/%s", attributes.Group(schema), attributes.Kind(schema))],
 		c.templates[""],
-	}
-
-	for _, templates := range templates {
+	} {
 		for _, t := range templates {
-			if t == nil {
-				continue
-			}
-			if schema.Formatter == nil {
-				schema.Formatter = t.Formatter
-			} else if t.Formatter != nil {
-				schema.Formatter = types2.FormatterChain(t.Formatter, schema.Formatter)
-			}
-			if schema.Store == nil {
-				if t.StoreFactory == nil {
-					schema.Store = t.Store
-				} else {
-					schema.Store = t.StoreFactory(c.defaultStore())
-				}
-			}
-			if t.Customize != nil {
-				t.Customize(schema)
+			if t != nil {
+				result = append(result, t)
 			}
 		}
 	}
+	return result
 }
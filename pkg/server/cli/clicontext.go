@@ -3,25 +3,55 @@ package cli
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/acorn-io/baaah/pkg/ratelimit"
 	"github.com/acorn-io/baaah/pkg/restconfig"
+	"github.com/acorn-io/brent/pkg/audit"
 	brentauth "github.com/acorn-io/brent/pkg/auth"
 	authcli "github.com/acorn-io/brent/pkg/auth/cli"
+	"github.com/acorn-io/brent/pkg/middleware"
 	"github.com/acorn-io/brent/pkg/server"
+	"github.com/acorn-io/brent/pkg/subscribe"
 	"github.com/acorn-io/cmd"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/client-go/kubernetes"
 )
 
 type Brent struct {
 	cmd.DebugLogging
 
-	Kubeconfig     string `env:"KUBECONFIG"`
-	Context        string `env:"CONTEXT"`
-	HttpListenPort int    `default:"9080"`
+	Kubeconfig                   string   `env:"KUBECONFIG"`
+	Context                      string   `env:"CONTEXT"`
+	HttpListenPort               int      `default:"9080" usage:"Port to serve HTTP on, only used with a TLS certificate when --http-with-tls is set"`
+	HttpWithTLS                  bool     `name:"http-with-tls" usage:"Keep serving plain HTTP next to HTTPS when a TLS certificate is set, credentials are then accepted in cleartext"`
+	HttpsListenPort              int      `name:"https-listen-port" default:"9443" usage:"Port to serve HTTPS on when a TLS certificate is set"`
+	TLSCertFile                  string   `name:"tls-cert-file" usage:"Certificate to serve HTTPS with, reloaded when it changes"`
+	TLSKeyFile                   string   `name:"tls-key-file" usage:"Private key of the TLS certificate, reloaded when it changes"`
+	Cache                        bool     `env:"CACHE"`
+	ContinueTokenKey             string   `env:"CONTINUE_TOKEN_KEY"`
+	SubscribeBufferSize          int      `default:"100" usage:"Number of events buffered per websocket session"`
+	SubscribeOverflow            string   `default:"disconnect" usage:"What to do when a websocket client falls behind: disconnect, coalesce or block"`
+	SubscribeBlockTimeoutSeconds int      `default:"10" usage:"How long the block overflow policy waits for a client"`
+	RateLimitReadQPS             string   `usage:"Reads per second allowed for each user, such as 0.5, 0 is unlimited"`
+	RateLimitReadBurst           int      `usage:"Reads a user can burst above the rate"`
+	RateLimitWriteQPS            string   `usage:"Writes per second allowed for each user, such as 0.5, 0 is unlimited"`
+	RateLimitWriteBurst          int      `usage:"Writes a user can burst above the rate"`
+	RateLimitWatchQPS            string   `usage:"Watches opened per second allowed for each user, such as 0.5, 0 is unlimited"`
+	RateLimitWatchBurst          int      `usage:"Watches a user can open in a burst above the rate"`
+	RateLimitExemptGroups        []string `usage:"Groups that are not rate limited"`
+	AuditLogPath                 string   `usage:"File audit events are appended to as JSON lines, - for stdout"`
+	AuditWebhookURL              string   `usage:"URL batches of audit events are posted to"`
+	AuditLevel                   string   `default:"Metadata" usage:"Audit level of mutating requests: None, Metadata or Request"`
+	AuditReadLevel               string   `default:"None" usage:"Audit level of get, list and watch requests: None, Metadata or Request"`
+	AuditMaxBodyBytes            int      `default:"65536" usage:"Most of a request body recorded at the Request audit level"`
+	AuditTrustedProxies          []string `usage:"IP addresses or CIDRs of proxies whose X-Forwarded-For header is used as the audited source IP"`
+	Impersonation                bool     `usage:"Allow callers that RBAC grants the impersonate verb to act as another user with the Impersonate-* headers"`
+	ImpersonationSAR             bool     `name:"impersonation-subject-access-review" usage:"Check impersonation with a SubjectAccessReview instead of the caller's RBAC access set"`
 
-	authcli.WebhookConfig
+	authcli.Config
 }
 
 func NewBrent() *cobra.Command {
@@ -30,7 +60,9 @@ func NewBrent() *cobra.Command {
 
 func (c *Brent) Run(cmd *cobra.Command, args []string) error {
 	var (
-		auth brentauth.Middleware
+		auth        brentauth.Middleware
+		auditSinks  audit.Sinks
+		auditPolicy audit.Policy
 	)
 
 	if err := c.DebugLogging.InitLogging(); err != nil {
@@ -43,21 +75,116 @@ func (c *Brent) Run(cmd *cobra.Command, args []string) error {
 	}
 	restConfig.RateLimiter = ratelimit.None
 
-	if c.WebhookConfig.WebhookAuthentication {
-		auth, err = c.WebhookConfig.WebhookMiddleware()
+	if c.ClientCertEnabled() && c.TLSCertFile == "" {
+		return fmt.Errorf("client certificate authentication requires a TLS certificate")
+	}
+	auth, err = c.Config.Middleware(cmd.Context(), restConfig)
+	if err != nil {
+		return err
+	}
+
+	if c.AuditLogPath != "" {
+		sink, err := audit.NewFileSink(c.AuditLogPath)
 		if err != nil {
 			return err
 		}
+		auditSinks = append(auditSinks, sink)
+	}
+	if c.AuditWebhookURL != "" {
+		auditSinks = append(auditSinks, audit.NewWebhookSink(cmd.Context(), c.AuditWebhookURL))
+	}
+	if auditPolicy.Level, err = audit.ParseLevel(c.AuditLevel); err != nil {
+		return err
+	}
+	if auditPolicy.ReadLevel, err = audit.ParseLevel(c.AuditReadLevel); err != nil {
+		return err
+	}
+	auditPolicy.MaxBodyBytes = c.AuditMaxBodyBytes
+	if auditPolicy.TrustedProxies, err = audit.ParseTrustedProxies(c.AuditTrustedProxies); err != nil {
+		return err
 	}
 
-	s, err := server.New(cmd.Context(), restConfig, &server.Options{
-		AuthMiddleware: auth,
-	})
+	overflow, err := subscribe.ParseOverflowPolicy(c.SubscribeOverflow)
+	if err != nil {
+		return err
+	}
+
+	var rateLimit middleware.RateLimitOptions
+	if rateLimit.Read, err = middleware.ParseRateLimit(c.RateLimitReadQPS, c.RateLimitReadBurst); err != nil {
+		return err
+	}
+	if rateLimit.Write, err = middleware.ParseRateLimit(c.RateLimitWriteQPS, c.RateLimitWriteBurst); err != nil {
+		return err
+	}
+	if rateLimit.Watch, err = middleware.ParseRateLimit(c.RateLimitWatchQPS, c.RateLimitWatchBurst); err != nil {
+		return err
+	}
+	rateLimit.ExemptGroups = c.RateLimitExemptGroups
+
+	opts := &server.Options{
+		AuthMiddleware:   auth,
+		Cache:            c.Cache,
+		ContinueTokenKey: []byte(c.ContinueTokenKey),
+		Subscribe: subscribe.Options{
+			BufferSize:   c.SubscribeBufferSize,
+			Overflow:     overflow,
+			BlockTimeout: time.Duration(c.SubscribeBlockTimeoutSeconds) * time.Second,
+		},
+		RateLimit:       rateLimit,
+		AuditPolicy:     auditPolicy,
+		Impersonation:   c.Impersonation,
+		APIKeyNamespace: c.APIKeyNamespace,
+		APIKeyMaxTTL:    c.APIKeyMaxTTL(),
+	}
+	if c.Impersonation && c.ImpersonationSAR {
+		k8s, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return err
+		}
+		opts.ImpersonationAuthorizer = brentauth.NewSubjectAccessReviewAuthorizer(k8s.AuthorizationV1())
+	}
+	if len(auditSinks) > 0 {
+		opts.AuditSink = auditSinks
+	}
+
+	s, err := server.New(cmd.Context(), restConfig, opts)
+	if err != nil {
+		return err
+	}
+
+	return c.serve(s)
+}
+
+func (c *Brent) serve(handler http.Handler) error {
+	if c.TLSCertFile == "" {
+		addr := fmt.Sprintf(":%d", c.HttpListenPort)
+		logrus.Info("Listening on " + addr)
+		return http.ListenAndServe(addr, handler)
+	}
+
+	tlsConfig, err := server.TLSConfig(c.TLSCertFile, c.TLSKeyFile, c.ClientCertEnabled())
 	if err != nil {
 		return err
 	}
 
-	addr := fmt.Sprintf(":%d", c.HttpListenPort)
-	logrus.Info("Listening on " + addr)
-	return http.ListenAndServe(addr, s)
+	var eg errgroup.Group
+	if c.HttpWithTLS && c.HttpListenPort > 0 {
+		addr := fmt.Sprintf(":%d", c.HttpListenPort)
+		logrus.Warn("Listening on " + addr + " without TLS, tokens sent to this port are not encrypted")
+		eg.Go(func() error {
+			return http.ListenAndServe(addr, handler)
+		})
+	}
+
+	httpsServer := &http.Server{
+		Addr:      fmt.Sprintf(":%d", c.HttpsListenPort),
+		Handler:   handler,
+		TLSConfig: tlsConfig,
+	}
+	logrus.Info("Listening on " + httpsServer.Addr + " with TLS")
+	eg.Go(func() error {
+		return httpsServer.ListenAndServeTLS("", "")
+	})
+
+	return eg.Wait()
 }
@@ -4,7 +4,9 @@ import (
 	"net/http"
 
 	"github.com/acorn-io/brent/pkg/accesscontrol"
+	"github.com/acorn-io/brent/pkg/audit"
 	"github.com/acorn-io/brent/pkg/auth"
+	"github.com/acorn-io/brent/pkg/middleware"
 	k8sproxy "github.com/acorn-io/brent/pkg/proxy"
 	"github.com/acorn-io/brent/pkg/schema"
 	"github.com/acorn-io/brent/pkg/server/router"
@@ -16,7 +18,7 @@ import (
 )
 
 func New(cfg *rest.Config, sf schema.Factory, authMiddleware auth.Middleware, next http.Handler,
-	routerFunc router.RouterFunc) (*Server, http.Handler, error) {
+	routerFunc router.RouterFunc, rateLimit middleware.RateLimitOptions, auditSink audit.Sink, auditPolicy audit.Policy) (*Server, http.Handler, error) {
 	var (
 		proxy http.Handler
 		err   error
@@ -39,6 +41,13 @@ func New(cfg *rest.Config, sf schema.Factory, authMiddleware auth.Middleware, ne
 	}
 
 	w := authMiddleware
+	if auditSink != nil {
+		// audit before rate limiting so rejected requests are recorded too
+		w = w.Chain(audit.Middleware(auditSink, auditPolicy))
+	}
+	if rateLimit.Enabled() {
+		w = w.Chain(middleware.NewRateLimiter(rateLimit).Middleware)
+	}
 	handlers := router.Handlers{
 		Next:        next,
 		K8sResource: w(a.apiHandler(k8sAPI)),
@@ -94,6 +103,26 @@ func (a *apiServer) apiHandler(apiFunc APIFunc) http.Handler {
 				apiFunc(a.sf, apiOp)
 			}
 			a.server.Handle(apiOp)
+			auditEvent(apiOp)
 		}
 	})
 }
+
+// auditEvent fills in the audit event of the request with the schema and ID it was parsed to
+func auditEvent(apiOp *types.APIRequest) {
+	event, ok := audit.EventFrom(apiOp.Context())
+	if !ok || apiOp.Type == "" {
+		return
+	}
+
+	event.Verb = audit.VerbForMethod(apiOp.Method, apiOp.Name != "")
+	if apiOp.Action != "" {
+		event.Verb = "action"
+		event.Subresource = apiOp.Action
+	} else if apiOp.Link != "" {
+		event.Subresource = apiOp.Link
+	}
+	event.Resource = apiOp.Type
+	event.Namespace = apiOp.Namespace
+	event.Name = apiOp.Name
+}
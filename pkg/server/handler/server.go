@@ -60,13 +60,21 @@ func defaultAPIServer() *Server {
 					Encoder:     types2.YAMLEncoder,
 				},
 			},
+			"csv": &writer2.GzipWriter{
+				ResponseWriter: &writer2.TableResponseWriter{
+					CSV: true,
+				},
+			},
+			"table": &writer2.GzipWriter{
+				ResponseWriter: &writer2.TableResponseWriter{},
+			},
 		},
 		AccessControl: &accesscontrol.SchemaBasedAccess{},
 		Parser:        parse2.Parse,
 		URLParser:     parse2.MuxURLParser,
 	}
 
-	subscribe.Register(s.Schemas, subscribe.DefaultGetter, os.Getenv("SERVER_VERSION"))
+	subscribe.Register(s.Schemas, subscribe.DefaultGetter, os.Getenv("SERVER_VERSION"), subscribe.Options{})
 	return s
 }
 
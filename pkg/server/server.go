@@ -4,17 +4,23 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/acorn-io/brent/pkg/accesscontrol"
+	"github.com/acorn-io/brent/pkg/apikey"
+	"github.com/acorn-io/brent/pkg/audit"
 	"github.com/acorn-io/brent/pkg/auth"
 	"github.com/acorn-io/brent/pkg/client"
 	schemacontroller "github.com/acorn-io/brent/pkg/controllers/schema"
+	"github.com/acorn-io/brent/pkg/middleware"
 	"github.com/acorn-io/brent/pkg/resources"
 	"github.com/acorn-io/brent/pkg/resources/common"
 	"github.com/acorn-io/brent/pkg/resources/schemas"
 	"github.com/acorn-io/brent/pkg/schema"
 	"github.com/acorn-io/brent/pkg/server/handler"
 	"github.com/acorn-io/brent/pkg/server/router"
+	"github.com/acorn-io/brent/pkg/stores/partition"
+	"github.com/acorn-io/brent/pkg/subscribe"
 	"github.com/acorn-io/brent/pkg/types"
 	"k8s.io/client-go/rest"
 )
@@ -33,10 +39,20 @@ type Server struct {
 	Version         string
 
 	authMiddleware      auth.Middleware
+	cache               bool
+	continueTokens      *partition.ContinueTokens
 	controllers         *Controllers
 	needControllerStart bool
 	next                http.Handler
 	router              router.RouterFunc
+	subscribeOptions    subscribe.Options
+	rateLimit           middleware.RateLimitOptions
+	auditSink           audit.Sink
+	auditPolicy         audit.Policy
+	impersonation       bool
+	impersonationAuthz  auth.ImpersonationAuthorizer
+	apiKeyNamespace     string
+	apiKeyMaxTTL        time.Duration
 }
 
 type Options struct {
@@ -48,6 +64,28 @@ type Options struct {
 	Next            http.Handler
 	Router          router.RouterFunc
 	ServerVersion   string
+	// Cache serves list and get requests from shared informers instead of querying the apiserver each time
+	Cache bool
+	// ContinueTokenKey signs pagination continue tokens. If empty a random key is generated on start, so tokens
+	// are not valid across restarts or replicas.
+	ContinueTokenKey []byte
+	// Subscribe configures the websocket event buffer and what happens when a client can not keep up
+	Subscribe subscribe.Options
+	// RateLimit limits the reads, writes and watches of each user, nothing is limited by default
+	RateLimit middleware.RateLimitOptions
+	// AuditSink receives a record of the requests AuditPolicy selects, nothing is audited if it is nil
+	AuditSink   audit.Sink
+	AuditPolicy audit.Policy
+	// Impersonation serves requests with Impersonate-* headers as the impersonated user if the caller holds the
+	// impersonate verb, checked by ImpersonationAuthorizer or the caller's access set if it is nil. It has no effect
+	// without an AuthMiddleware.
+	Impersonation           bool
+	ImpersonationAuthorizer auth.ImpersonationAuthorizer
+	// APIKeyNamespace adds the apikeys schema, which lets users create and revoke their own API keys stored as
+	// Secrets in this namespace. Authenticating them is up to the AuthMiddleware, see apikey.NewAuthenticator.
+	APIKeyNamespace string
+	// APIKeyMaxTTL is the longest API keys can be valid for, apikey.DefaultMaxTTL if it is not set
+	APIKeyMaxTTL time.Duration
 }
 
 func New(ctx context.Context, restConfig *rest.Config, opts *Options) (*Server, error) {
@@ -56,14 +94,24 @@ func New(ctx context.Context, restConfig *rest.Config, opts *Options) (*Server,
 	}
 
 	server := &Server{
-		RESTConfig:      restConfig,
-		ClientFactory:   opts.ClientFactory,
-		AccessSetLookup: opts.AccessSetLookup,
-		authMiddleware:  opts.AuthMiddleware,
-		controllers:     opts.Controllers,
-		next:            opts.Next,
-		router:          opts.Router,
-		Version:         opts.ServerVersion,
+		RESTConfig:         restConfig,
+		ClientFactory:      opts.ClientFactory,
+		AccessSetLookup:    opts.AccessSetLookup,
+		authMiddleware:     opts.AuthMiddleware,
+		cache:              opts.Cache,
+		continueTokens:     partition.NewContinueTokens(opts.ContinueTokenKey),
+		controllers:        opts.Controllers,
+		next:               opts.Next,
+		router:             opts.Router,
+		subscribeOptions:   opts.Subscribe,
+		rateLimit:          opts.RateLimit,
+		auditSink:          opts.AuditSink,
+		auditPolicy:        opts.AuditPolicy,
+		impersonation:      opts.Impersonation,
+		impersonationAuthz: opts.ImpersonationAuthorizer,
+		apiKeyNamespace:    opts.APIKeyNamespace,
+		apiKeyMaxTTL:       opts.APIKeyMaxTTL,
+		Version:            opts.ServerVersion,
 	}
 
 	if err := setup(ctx, server); err != nil {
@@ -123,11 +171,15 @@ func setup(ctx context.Context, server *Server) error {
 
 	sf := schema.NewCollection(ctx, server.BaseSchemas, asl)
 
-	if err = resources.DefaultSchemas(server.BaseSchemas, sf, server.Version); err != nil {
+	if err = resources.DefaultSchemas(server.BaseSchemas, sf, server.Version, server.subscribeOptions); err != nil {
 		return err
 	}
 
-	for _, template := range resources.DefaultSchemaTemplates(cf, asl, server.controllers.K8s.Discovery()) {
+	if server.apiKeyNamespace != "" {
+		apikey.Register(server.BaseSchemas, server.controllers.K8s.CoreV1().Secrets(server.apiKeyNamespace), server.apiKeyMaxTTL)
+	}
+
+	for _, template := range resources.DefaultSchemaTemplates(cf, asl, server.controllers.K8s.Discovery(), server.cache, server.continueTokens) {
 		sf.AddTemplate(template)
 	}
 
@@ -144,7 +196,17 @@ func setup(ctx context.Context, server *Server) error {
 		server.controllers.Router,
 		sf)
 
-	apiServer, handler, err := handler.New(server.RESTConfig, sf, server.authMiddleware, server.next, server.router)
+	authMiddleware := server.authMiddleware
+	if server.impersonation && authMiddleware != nil {
+		authz := server.impersonationAuthz
+		if authz == nil {
+			authz = accesscontrol.ImpersonationAuthorizer(asl)
+		}
+		authMiddleware = authMiddleware.Chain(auth.NewImpersonationMiddleware(authz))
+	}
+
+	apiServer, handler, err := handler.New(server.RESTConfig, sf, authMiddleware, server.next, server.router, server.rateLimit,
+		server.auditSink, server.auditPolicy)
 	if err != nil {
 		return err
 	}
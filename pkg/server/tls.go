@@ -0,0 +1,98 @@
+package server
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// how often the certificate and key files are checked for changes
+const certReloadInterval = 10 * time.Second
+
+// CertificateReloader serves a certificate and key from files, loading them again when either changes so renewed
+// certificates are used without a restart
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+
+	lock      sync.Mutex
+	cert      *tls.Certificate
+	modTimes  [2]time.Time
+	lastCheck time.Time
+}
+
+func NewCertificateReloader(certFile, keyFile string) (*CertificateReloader, error) {
+	c := &CertificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if _, err := c.GetCertificate(nil); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate. If the files can no longer be loaded the last certificate is
+// kept.
+func (c *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.cert != nil && time.Since(c.lastCheck) < certReloadInterval {
+		return c.cert, nil
+	}
+	c.lastCheck = time.Now()
+
+	if err := c.load(); err != nil {
+		if c.cert == nil {
+			return nil, err
+		}
+		logrus.Errorf("failed to reload TLS certificate %s, keeping the current certificate: %v", c.certFile, err)
+	}
+	return c.cert, nil
+}
+
+func (c *CertificateReloader) load() error {
+	var modTimes [2]time.Time
+	for i, file := range []string{c.certFile, c.keyFile} {
+		stat, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[i] = stat.ModTime()
+	}
+	if c.cert != nil && modTimes == c.modTimes {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+	if c.cert != nil {
+		logrus.Infof("reloaded TLS certificate %s", c.certFile)
+	}
+	c.cert, c.modTimes = &cert, modTimes
+	return nil
+}
+
+// TLSConfig serves the reloading certificate. If requestClientCerts is true clients are asked for a certificate,
+// which is verified by the client certificate authenticator rather than the handshake so the CAs can be reloaded too.
+func TLSConfig(certFile, keyFile string, requestClientCerts bool) (*tls.Config, error) {
+	reloader, err := NewCertificateReloader(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+	if requestClientCerts {
+		cfg.ClientAuth = tls.RequestClientCert
+	}
+	return cfg, nil
+}
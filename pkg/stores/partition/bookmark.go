@@ -0,0 +1,48 @@
+package partition
+
+import (
+	"strconv"
+	"sync"
+)
+
+// bookmarks merges the bookmarks of several partition watches. A bookmark is only forwarded once every
+// partition has reached its revision, so resuming from it can not skip events of a slower partition.
+type bookmarks struct {
+	lock      sync.Mutex
+	revisions []uint64
+	sent      uint64
+}
+
+func newBookmarks(partitions int) *bookmarks {
+	return &bookmarks{
+		revisions: make([]uint64, partitions),
+	}
+}
+
+// update records that partition has reached revision and returns the revision to send to the client, if any.
+func (b *bookmarks) update(partition int, revision string) (string, bool) {
+	rev, err := strconv.ParseUint(revision, 10, 64)
+	if err != nil {
+		// revisions are opaque to clients, if they can not be compared just pass them through
+		return revision, true
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if rev > b.revisions[partition] {
+		b.revisions[partition] = rev
+	}
+
+	min := b.revisions[0]
+	for _, r := range b.revisions[1:] {
+		if r < min {
+			min = r
+		}
+	}
+	if min == 0 || min <= b.sent {
+		return "", false
+	}
+	b.sent = min
+	return strconv.FormatUint(min, 10), true
+}
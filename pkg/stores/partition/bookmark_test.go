@@ -0,0 +1,59 @@
+package partition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookmarks(t *testing.T) {
+	type update struct {
+		partition int
+		revision  string
+		want      string
+		wantOK    bool
+	}
+
+	tests := []struct {
+		name       string
+		partitions int
+		updates    []update
+	}{
+		{
+			name:       "single partition passes through",
+			partitions: 1,
+			updates: []update{
+				{0, "10", "10", true},
+				{0, "12", "12", true},
+			},
+		},
+		{
+			name:       "waits for every partition",
+			partitions: 2,
+			updates: []update{
+				{0, "10", "", false},
+				{1, "8", "8", true},
+				{1, "15", "10", true},
+				{1, "16", "", false},
+				{0, "20", "16", true},
+			},
+		},
+		{
+			name:       "opaque revisions pass through",
+			partitions: 2,
+			updates: []update{
+				{0, "abc", "abc", true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBookmarks(tt.partitions)
+			for _, u := range tt.updates {
+				got, ok := b.update(u.partition, u.revision)
+				assert.Equal(t, u.wantOK, ok)
+				assert.Equal(t, u.want, got)
+			}
+		})
+	}
+}
@@ -2,8 +2,6 @@ package partition
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
 
 	"github.com/acorn-io/brent/pkg/types"
 	"golang.org/x/sync/errgroup"
@@ -18,9 +16,13 @@ type ParallelPartitionLister struct {
 	Lister      PartitionLister
 	Concurrency int64
 	Partitions  []Partition
-	state       *listState
-	revision    string
-	err         error
+	// Tokens signs continue tokens, if nil a process wide random key is used
+	Tokens *ContinueTokens
+	// User is the identity continue tokens are bound to
+	User     string
+	state    *listState
+	revision string
+	err      error
 }
 
 type PartitionLister func(ctx context.Context, partition Partition, cont string, revision string, limit int) (types.APIObjectList, error)
@@ -37,11 +39,14 @@ func (p *ParallelPartitionLister) Continue() string {
 	if p.state == nil {
 		return ""
 	}
-	bytes, err := json.Marshal(p.state)
-	if err != nil {
-		return ""
+	return p.tokens().encode(*p.state, p.User)
+}
+
+func (p *ParallelPartitionLister) tokens() *ContinueTokens {
+	if p.Tokens == nil {
+		return defaultContinueTokens
 	}
-	return base64.StdEncoding.EncodeToString(bytes)
+	return p.Tokens
 }
 
 func indexOrZero(partitions []Partition, name string) int {
@@ -59,13 +64,11 @@ func indexOrZero(partitions []Partition, name string) int {
 func (p *ParallelPartitionLister) List(ctx context.Context, limit int, resume string) (<-chan []types.APIObject, error) {
 	var state listState
 	if resume != "" {
-		bytes, err := base64.StdEncoding.DecodeString(resume)
+		var err error
+		state, err = p.tokens().decode(resume, p.User)
 		if err != nil {
 			return nil, err
 		}
-		if err := json.Unmarshal(bytes, &state); err != nil {
-			return nil, err
-		}
 
 		if state.Limit > 0 {
 			limit = state.Limit
@@ -2,13 +2,22 @@ package partition
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/parse"
 	types2 "github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/validation"
 	"golang.org/x/sync/errgroup"
 )
 
+// maxSortedObjects bounds how many objects are read from all partitions to sort a list
+const maxSortedObjects = 100000
+
+var TooManyObjectsToSort = validation.ErrorCode{Code: "TooManyObjectsToSort", Status: http.StatusBadRequest}
+
 type Partitioner interface {
 	Lookup(apiOp *types2.APIRequest, schema *types2.APISchema, verb, id string) (Partition, error)
 	All(apiOp *types2.APIRequest, schema *types2.APISchema, verb, id string) ([]Partition, error)
@@ -17,6 +26,8 @@ type Partitioner interface {
 
 type Store struct {
 	Partitioner Partitioner
+	// Tokens signs continue tokens, if nil a process wide random key is used
+	Tokens *ContinueTokens
 }
 
 func (s *Store) getStore(apiOp *types2.APIRequest, schema *types2.APISchema, verb, id string) (types2.Store, error) {
@@ -85,11 +96,17 @@ func (s *Store) List(apiOp *types2.APIRequest, schema *types2.APISchema) (types2
 		},
 		Concurrency: 3,
 		Partitions:  paritions,
+		Tokens:      s.Tokens,
+		User:        apiOp.GetUser(),
 	}
 
 	resume := apiOp.Request.URL.Query().Get("continue")
 	limit := getLimit(apiOp.Request)
 
+	if sorts := parse.Sorts(apiOp.Request.URL.Query()); len(sorts) > 0 {
+		return listSorted(apiOp.Context(), &lister, sorts, limit, resume)
+	}
+
 	list, err := lister.List(apiOp.Context(), limit, resume)
 	if err != nil {
 		return result, err
@@ -133,22 +150,31 @@ func (s *Store) Watch(apiOp *types2.APIRequest, schema *types2.APISchema, wr typ
 
 	eg := errgroup.Group{}
 	response := make(chan types2.APIEvent)
+	marks := newBookmarks(len(partitions))
 
-	for _, partition := range partitions {
+	for i, partition := range partitions {
 		store, err := s.Partitioner.Store(apiOp, partition)
 		if err != nil {
 			cancel()
 			return nil, err
 		}
 
+		i := i
 		eg.Go(func() error {
 			defer cancel()
 			c, err := store.Watch(apiOp, schema, wr)
 			if err != nil {
 				return err
 			}
-			for i := range c {
-				response <- i
+			for event := range c {
+				if event.Name == types2.BookmarkAPIEvent {
+					rev, ok := marks.update(i, event.Revision)
+					if !ok {
+						continue
+					}
+					event.Revision = rev
+				}
+				response <- event
 			}
 			return nil
 		})
@@ -164,6 +190,66 @@ func (s *Store) Watch(apiOp *types2.APIRequest, schema *types2.APISchema, wr typ
 	return response, nil
 }
 
+// listSorted reads every partition, sorts the merged result and then pages through it by offset. Lists of more than
+// maxSortedObjects are refused rather than sorted partially. The partitions are read again for every page at the
+// latest revision, as not every partition store can list at an older one, so objects created or deleted between
+// pages can shift the offsets and be skipped or repeated.
+func listSorted(ctx context.Context, lister *ParallelPartitionLister, sorts []parse.Sort, limit int, resume string) (types2.APIObjectList, error) {
+	var (
+		result types2.APIObjectList
+		state  listState
+	)
+
+	if resume != "" {
+		var err error
+		state, err = lister.tokens().decode(resume, lister.User)
+		if err != nil {
+			return result, err
+		}
+		if state.Limit > 0 {
+			limit = state.Limit
+		}
+	}
+
+	// read one more than the maximum to know if there are more
+	list, err := lister.List(ctx, maxSortedObjects+1, "")
+	if err != nil {
+		return result, err
+	}
+
+	var objs []types2.APIObject
+	for items := range list {
+		objs = append(objs, items...)
+	}
+	if err := lister.Err(); err != nil {
+		return result, err
+	}
+	if len(objs) > maxSortedObjects {
+		return result, apierror.NewAPIError(TooManyObjectsToSort,
+			fmt.Sprintf("more than %d objects to sort, narrow the list with a namespace, label selector or filter", maxSortedObjects))
+	}
+
+	parse.SortObjects(sorts, objs)
+
+	offset := state.Offset
+	if offset > len(objs) {
+		offset = len(objs)
+	}
+	end := len(objs)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+		result.Continue = lister.tokens().encode(listState{
+			Revision: lister.Revision(),
+			Offset:   end,
+			Limit:    limit,
+		}, lister.User)
+	}
+
+	result.Objects = objs[offset:end]
+	result.Revision = lister.Revision()
+	return result, nil
+}
+
 func getLimit(req *http.Request) int {
 	limitString := req.URL.Query().Get("limit")
 	limit, err := strconv.Atoi(limitString)
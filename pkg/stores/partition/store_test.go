@@ -0,0 +1,90 @@
+package partition
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/parse"
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPartition string
+
+func (t testPartition) Name() string {
+	return string(t)
+}
+
+func objects(names ...string) []types.APIObject {
+	var result []types.APIObject
+	for _, name := range names {
+		result = append(result, types.APIObject{
+			ID:     name,
+			Object: map[string]interface{}{"metadata": map[string]interface{}{"name": name}},
+		})
+	}
+	return result
+}
+
+func TestListSorted(t *testing.T) {
+	lists := map[string][]types.APIObject{
+		"a": objects("d", "b"),
+		"b": objects("c", "a"),
+	}
+	lister := func() *ParallelPartitionLister {
+		return &ParallelPartitionLister{
+			Lister: func(_ context.Context, partition Partition, _ string, _ string, _ int) (types.APIObjectList, error) {
+				return types.APIObjectList{Revision: "1", Objects: lists[partition.Name()]}, nil
+			},
+			Concurrency: 3,
+			Partitions:  []Partition{testPartition("a"), testPartition("b")},
+			User:        "alice",
+		}
+	}
+	sorts := []parse.Sort{{Field: []string{"metadata", "name"}}}
+
+	ids := func(list types.APIObjectList) []string {
+		var result []string
+		for _, obj := range list.Objects {
+			result = append(result, obj.ID)
+		}
+		return result
+	}
+
+	first, err := listSorted(context.Background(), lister(), sorts, 3, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, ids(first))
+	require.NotEmpty(t, first.Continue)
+
+	second, err := listSorted(context.Background(), lister(), sorts, 0, first.Continue)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d"}, ids(second))
+	assert.Empty(t, second.Continue)
+}
+
+func TestListSortedTooMany(t *testing.T) {
+	names := make([]string, maxSortedObjects)
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+	}
+	page := objects(names...)
+
+	lister := &ParallelPartitionLister{
+		Lister: func(_ context.Context, _ Partition, cont string, _ string, _ int) (types.APIObjectList, error) {
+			if cont == "" {
+				return types.APIObjectList{Objects: page, Continue: "more"}, nil
+			}
+			return types.APIObjectList{Objects: objects("last")}, nil
+		},
+		Concurrency: 3,
+		Partitions:  []Partition{testPartition("a")},
+	}
+
+	_, err := listSorted(context.Background(), lister, []parse.Sort{{Field: []string{"metadata", "name"}}}, 10, "")
+	if apiErr, ok := err.(*apierror.APIError); assert.True(t, ok, "%v", err) {
+		assert.Equal(t, TooManyObjectsToSort, apiErr.Code)
+	}
+}
@@ -0,0 +1,108 @@
+package partition
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/schemer/validation"
+	"github.com/sirupsen/logrus"
+)
+
+const continueTokenTTL = time.Hour
+
+var (
+	InvalidContinueToken = validation.ErrorCode{Code: "InvalidContinueToken", Status: http.StatusBadRequest}
+
+	defaultContinueTokens = NewContinueTokens(nil)
+)
+
+// ContinueTokens signs the pagination state handed to clients so it can not be modified, replayed by another
+// user or used after it expires.
+type ContinueTokens struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewContinueTokens signs tokens with key. If key is empty a random key is generated, in which case tokens
+// are only valid for this process.
+func NewContinueTokens(key []byte) *ContinueTokens {
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			logrus.Fatalf("failed to generate continue token key: %v", err)
+		}
+	}
+	return &ContinueTokens{
+		key: key,
+		ttl: continueTokenTTL,
+		now: time.Now,
+	}
+}
+
+type signedState struct {
+	State   listState `json:"s"`
+	User    string    `json:"u,omitempty"`
+	Expires int64     `json:"e"`
+}
+
+func (c *ContinueTokens) sign(payload string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func userHash(user string) string {
+	if user == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(user))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (c *ContinueTokens) encode(state listState, user string) string {
+	bytes, err := json.Marshal(signedState{
+		State:   state,
+		User:    userHash(user),
+		Expires: c.now().Add(c.ttl).Unix(),
+	})
+	if err != nil {
+		return ""
+	}
+	payload := base64.RawURLEncoding.EncodeToString(bytes)
+	return payload + "." + c.sign(payload)
+}
+
+func (c *ContinueTokens) decode(token, user string) (listState, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(c.sign(payload))) {
+		return listState{}, apierror.NewAPIError(InvalidContinueToken, "continue token is invalid")
+	}
+
+	bytes, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return listState{}, apierror.NewAPIError(InvalidContinueToken, "continue token is invalid")
+	}
+
+	var signed signedState
+	if err := json.Unmarshal(bytes, &signed); err != nil {
+		return listState{}, apierror.NewAPIError(InvalidContinueToken, "continue token is invalid")
+	}
+
+	if signed.User != userHash(user) {
+		return listState{}, apierror.NewAPIError(InvalidContinueToken, "continue token was issued to a different user")
+	}
+
+	if c.now().Unix() > signed.Expires {
+		return listState{}, apierror.NewAPIError(InvalidContinueToken, "continue token has expired, restart the list")
+	}
+
+	return signed.State, nil
+}
@@ -0,0 +1,86 @@
+package partition
+
+import (
+	"testing"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContinueTokens(t *testing.T) {
+	now := time.Now()
+	tokens := NewContinueTokens([]byte("key"))
+	tokens.now = func() time.Time { return now }
+
+	state := listState{
+		Revision:      "10",
+		PartitionName: "default",
+		Continue:      "abc",
+		Offset:        5,
+		Limit:         10,
+	}
+	token := tokens.encode(state, "alice")
+
+	tests := []struct {
+		name    string
+		token   string
+		user    string
+		after   time.Duration
+		tokens  *ContinueTokens
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			token: token,
+			user:  "alice",
+		},
+		{
+			name:    "different user",
+			token:   token,
+			user:    "bob",
+			wantErr: "continue token was issued to a different user",
+		},
+		{
+			name:    "expired",
+			token:   token,
+			user:    "alice",
+			after:   continueTokenTTL + time.Second,
+			wantErr: "continue token has expired, restart the list",
+		},
+		{
+			name:    "modified payload",
+			token:   "x" + token,
+			user:    "alice",
+			wantErr: "continue token is invalid",
+		},
+		{
+			name:    "unsigned",
+			token:   "eyJyIjoiMTAifQ==",
+			user:    "alice",
+			wantErr: "continue token is invalid",
+		},
+		{
+			name:    "different key",
+			token:   NewContinueTokens([]byte("other")).encode(state, "alice"),
+			user:    "alice",
+			wantErr: "continue token is invalid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens.now = func() time.Time { return now.Add(tt.after) }
+			got, err := tokens.decode(tt.token, tt.user)
+			if tt.wantErr != "" {
+				apiErr, ok := err.(*apierror.APIError)
+				if assert.True(t, ok, "expected an APIError, got %v", err) {
+					assert.Equal(t, InvalidContinueToken, apiErr.Code)
+					assert.Equal(t, tt.wantErr, apiErr.Message)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, state, got)
+		})
+	}
+}
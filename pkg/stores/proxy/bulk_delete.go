@@ -0,0 +1,281 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/acorn-io/brent/pkg/accesscontrol"
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/attributes"
+	"github.com/acorn-io/brent/pkg/parse"
+	types2 "github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/data/convert"
+	"github.com/acorn-io/schemer/validation"
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	bulkDeleteConcurrency = 10
+
+	BulkDeleted = "deleted"
+	BulkFailed  = "failed"
+)
+
+type bulkDeleteInput struct {
+	IDs               []string                    `json:"ids"`
+	PropagationPolicy *metav1.DeletionPropagation `json:"propagationPolicy,omitempty"`
+}
+
+type bulkDeleteResult struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+type deleteTarget struct {
+	namespace string
+	name      string
+}
+
+func (d deleteTarget) id() string {
+	if d.namespace == "" {
+		return d.name
+	}
+	return d.namespace + "/" + d.name
+}
+
+// BulkDeleteHandler handles the bulkDelete collection action. The body lists the IDs to delete and the response
+// has the status of every object.
+func BulkDeleteHandler(clientGetter ClientGetter) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		apiOp := types2.GetAPIContext(req.Context())
+		result, err := bulkDeleteByID(apiOp, clientGetter)
+		if err != nil {
+			apiOp.WriteError(translateError(err))
+			return
+		}
+		apiOp.WriteResponse(http.StatusOK, result)
+	})
+}
+
+// DeleteBySelector deletes every object of the collection matching the labelSelector query parameter, in every
+// namespace the user can delete in.
+func DeleteBySelector(clientGetter ClientGetter) types2.RequestHandler {
+	return func(apiOp *types2.APIRequest) (types2.APIObject, error) {
+		if err := apiOp.AccessControl.CanDelete(apiOp, types2.APIObject{}, apiOp.Schema); err != nil {
+			return types2.APIObject{}, err
+		}
+
+		opts, err := deleteOptions(apiOp)
+		if err != nil {
+			return types2.APIObject{}, err
+		}
+
+		targets, results, err := selectorTargets(apiOp, apiOp.Schema, clientGetter)
+		if err != nil {
+			return types2.APIObject{}, err
+		}
+
+		results = append(results, deleteTargets(apiOp, apiOp.Schema, clientGetter, targets, opts)...)
+		return toBulkDeleteObject(results), nil
+	}
+}
+
+func bulkDeleteByID(apiOp *types2.APIRequest, clientGetter ClientGetter) (types2.APIObject, error) {
+	if err := apiOp.AccessControl.CanDelete(apiOp, types2.APIObject{}, apiOp.Schema); err != nil {
+		return types2.APIObject{}, err
+	}
+
+	body, err := parse.ReadBody(apiOp.Request)
+	if err != nil {
+		return types2.APIObject{}, err
+	}
+
+	var input bulkDeleteInput
+	if err := convert.ToObj(body.Data(), &input); err != nil {
+		return types2.APIObject{}, apierror.NewAPIError(validation.InvalidBodyContent, err.Error())
+	}
+	if len(input.IDs) == 0 {
+		return types2.APIObject{}, apierror.NewAPIError(validation.MissingRequired, "ids is required")
+	}
+
+	opts, err := deleteOptions(apiOp)
+	if err != nil {
+		return types2.APIObject{}, err
+	}
+	if input.PropagationPolicy != nil {
+		opts.PropagationPolicy = input.PropagationPolicy
+	}
+
+	targets, results := idTargets(apiOp, apiOp.Schema, input.IDs)
+	results = append(results, deleteTargets(apiOp, apiOp.Schema, clientGetter, targets, opts)...)
+	return toBulkDeleteObject(results), nil
+}
+
+func deleteOptions(apiOp *types2.APIRequest) (metav1.DeleteOptions, error) {
+	opts := metav1.DeleteOptions{}
+	return opts, decodeParams(apiOp, &opts)
+}
+
+func toBulkDeleteObject(results []bulkDeleteResult) types2.APIObject {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID < results[j].ID
+	})
+	return types2.APIObject{
+		Type: "bulkDelete",
+		Object: map[string]interface{}{
+			"results": results,
+		},
+	}
+}
+
+// idTargets resolves the IDs to the objects to delete. IDs the access set does not grant delete on are returned as
+// failed results.
+func idTargets(apiOp *types2.APIRequest, schema *types2.APISchema, ids []string) (targets []deleteTarget, failed []bulkDeleteResult) {
+	access, _ := attributes.Access(schema).(accesscontrol.AccessListByVerb)
+	namespaced := attributes.Namespaced(schema)
+
+	seen := map[deleteTarget]bool{}
+	for _, id := range ids {
+		target := deleteTarget{name: id}
+		if ns, name, ok := strings.Cut(id, "/"); ok {
+			target = deleteTarget{namespace: ns, name: name}
+		} else if namespaced {
+			target.namespace = apiOp.Namespace
+		}
+
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+
+		switch {
+		case target.name == "":
+			failed = append(failed, bulkDeleteResult{ID: id, Status: BulkFailed, Message: "name is required"})
+		case namespaced && target.namespace == "":
+			failed = append(failed, bulkDeleteResult{ID: id, Status: BulkFailed, Message: "namespace is required"})
+		case !namespaced && target.namespace != "":
+			failed = append(failed, bulkDeleteResult{ID: id, Status: BulkFailed, Message: fmt.Sprintf("%s is not namespaced", schema.ID)})
+		case !access.Grants("delete", target.namespace, target.name):
+			failed = append(failed, bulkDeleteResult{ID: id, Status: BulkFailed, Message: fmt.Sprintf("can not delete %s %s", schema.ID, id)})
+		default:
+			targets = append(targets, target)
+		}
+	}
+
+	return targets, failed
+}
+
+// selectorTargets lists the objects matching the label selector in every partition the user can delete in. The
+// objects are listed as admin because a user allowed to delete objects by name usually can not list them, only the
+// objects the user can delete are returned. When the user can delete in several namespaces, a namespace that fails
+// to list is returned as a failed result with the namespace as ID and the other namespaces are still deleted in. A
+// single partition that fails to list fails the request.
+func selectorTargets(apiOp *types2.APIRequest, schema *types2.APISchema, clientGetter ClientGetter) (targets []deleteTarget, failed []bulkDeleteResult, err error) {
+	selector := apiOp.Request.URL.Query().Get("labelSelector")
+	if selector == "" {
+		return nil, nil, apierror.NewAPIError(validation.MissingRequired, "labelSelector is required to delete a collection")
+	}
+
+	access, _ := attributes.Access(schema).(accesscontrol.AccessListByVerb)
+	partitions, passthrough := isPassthrough(apiOp, schema, "delete")
+	if passthrough {
+		partitions = passthroughPartitions
+	}
+
+	for _, p := range partitions {
+		p := p.(Partition)
+
+		namespace := p.Namespace
+		if p.Passthrough || namespace == accesscontrol.All {
+			namespace = apiOp.Namespace
+		}
+
+		list, err := listSelected(apiOp, schema, clientGetter, namespace, selector)
+		if err != nil && len(partitions) == 1 {
+			return nil, nil, err
+		} else if err != nil {
+			failed = append(failed, bulkDeleteResult{
+				ID:      namespace,
+				Status:  BulkFailed,
+				Message: fmt.Sprintf("can not list %s in %s: %s", schema.ID, namespace, errorMessage(err)),
+			})
+			continue
+		}
+
+		for _, obj := range list.Items {
+			if !p.Passthrough && !p.All && !p.Names.Has(obj.GetName()) {
+				continue
+			}
+			if !access.Grants("delete", obj.GetNamespace(), obj.GetName()) {
+				continue
+			}
+			targets = append(targets, deleteTarget{
+				namespace: obj.GetNamespace(),
+				name:      obj.GetName(),
+			})
+		}
+	}
+
+	return targets, failed, nil
+}
+
+func listSelected(apiOp *types2.APIRequest, schema *types2.APISchema, clientGetter ClientGetter, namespace, selector string) (*unstructured.UnstructuredList, error) {
+	k8sClient, err := clientGetter.AdminClient(apiOp, schema, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return k8sClient.List(apiOp.Context(), metav1.ListOptions{LabelSelector: selector})
+}
+
+// deleteTargets deletes the targets in parallel. Unlike Store.Delete it does not look the object up afterwards,
+// the status of each delete is the result.
+func deleteTargets(apiOp *types2.APIRequest, schema *types2.APISchema, clientGetter ClientGetter, targets []deleteTarget, opts metav1.DeleteOptions) []bulkDeleteResult {
+	results := make([]bulkDeleteResult, len(targets))
+	clients := map[string]dynamic.ResourceInterface{}
+
+	eg := errgroup.Group{}
+	eg.SetLimit(bulkDeleteConcurrency)
+	for i, target := range targets {
+		i, target := i, target
+		results[i] = bulkDeleteResult{
+			ID:     target.id(),
+			Status: BulkDeleted,
+		}
+
+		k8sClient, ok := clients[target.namespace]
+		if !ok {
+			var err error
+			k8sClient, err = clientGetter.Client(apiOp, schema, target.namespace)
+			if err != nil {
+				results[i].Status = BulkFailed
+				results[i].Message = errorMessage(err)
+				continue
+			}
+			clients[target.namespace] = k8sClient
+		}
+
+		eg.Go(func() error {
+			if err := k8sClient.Delete(apiOp.Context(), target.name, opts); err != nil {
+				results[i].Status = BulkFailed
+				results[i].Message = errorMessage(translateError(err))
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	return results
+}
+
+func errorMessage(err error) string {
+	if apiErr, ok := err.(*apierror.APIError); ok {
+		return apiErr.Message
+	}
+	return err.Error()
+}
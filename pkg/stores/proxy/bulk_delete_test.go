@@ -0,0 +1,129 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/acorn-io/brent/pkg/accesscontrol"
+	"github.com/acorn-io/brent/pkg/attributes"
+	types2 "github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// userClientGetter returns the clients of a fake dynamic client as the clients of the user
+type userClientGetter struct {
+	ClientGetter
+
+	client dynamic.Interface
+}
+
+func (u *userClientGetter) Client(_ *types2.APIRequest, _ *types2.APISchema, namespace string) (dynamic.ResourceInterface, error) {
+	return u.client.Resource(configMapGVR).Namespace(namespace), nil
+}
+
+func TestIDTargets(t *testing.T) {
+	schema := &types2.APISchema{Schema: &schemas.Schema{ID: "configmap"}}
+	attributes.SetNamespaced(schema, true)
+	attributes.SetAccess(schema, accesscontrol.AccessListByVerb{
+		"delete": accesscontrol.AccessList{
+			{Namespace: "dev", ResourceName: accesscontrol.All},
+			{Namespace: "prod", ResourceName: "allowed"},
+		},
+	})
+
+	apiOp := &types2.APIRequest{Namespace: "dev"}
+	targets, failed := idTargets(apiOp, schema, []string{
+		"dev/a",
+		"b",
+		"dev/b",
+		"prod/allowed",
+		"prod/other",
+		"test/c",
+		"dev/",
+	})
+
+	assert.Equal(t, []deleteTarget{
+		{namespace: "dev", name: "a"},
+		{namespace: "dev", name: "b"},
+		{namespace: "prod", name: "allowed"},
+	}, targets)
+	assert.Equal(t, []bulkDeleteResult{
+		{ID: "prod/other", Status: BulkFailed, Message: "can not delete configmap prod/other"},
+		{ID: "test/c", Status: BulkFailed, Message: "can not delete configmap test/c"},
+		{ID: "dev/", Status: BulkFailed, Message: "name is required"},
+	}, failed)
+}
+
+func TestIDTargetsClusterScoped(t *testing.T) {
+	schema := &types2.APISchema{Schema: &schemas.Schema{ID: "node"}}
+	attributes.SetAccess(schema, accesscontrol.AccessListByVerb{
+		"delete": accesscontrol.AccessList{
+			{Namespace: accesscontrol.All, ResourceName: accesscontrol.All},
+		},
+	})
+
+	targets, failed := idTargets(&types2.APIRequest{}, schema, []string{"a", "ns/b"})
+	assert.Equal(t, []deleteTarget{{name: "a"}}, targets)
+	assert.Equal(t, []bulkDeleteResult{
+		{ID: "ns/b", Status: BulkFailed, Message: "node is not namespaced"},
+	}, failed)
+}
+
+func TestSelectorTargets(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		configMap("dev", "a"),
+		configMap("prod", "b"),
+		configMap("test", "c"),
+		configMap("test", "d"),
+	)
+	client.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "prod" {
+			return true, nil, fmt.Errorf("prod is unavailable")
+		}
+		return false, nil, nil
+	})
+	// the objects are listed as admin, the user may only be allowed to delete them
+	getter := &adminClientGetter{client: client}
+
+	apiOp := func() *types2.APIRequest {
+		return &types2.APIRequest{
+			Request: httptest.NewRequest(http.MethodDelete, "/v1/configmaps?labelSelector=!stale", nil),
+		}
+	}
+	schema := func(access accesscontrol.AccessList) *types2.APISchema {
+		schema := &types2.APISchema{Schema: &schemas.Schema{ID: "configmap"}}
+		attributes.SetNamespaced(schema, true)
+		attributes.SetAccess(schema, accesscontrol.AccessListByVerb{"delete": access})
+		return schema
+	}
+
+	targets, failed, err := selectorTargets(apiOp(), schema(accesscontrol.AccessList{
+		{Namespace: "dev", ResourceName: accesscontrol.All},
+		{Namespace: "prod", ResourceName: accesscontrol.All},
+	}), getter)
+	require.NoError(t, err)
+	assert.Equal(t, []deleteTarget{{namespace: "dev", name: "a"}}, targets)
+	assert.Equal(t, []bulkDeleteResult{
+		{ID: "prod", Status: BulkFailed, Message: "can not list configmap in prod: prod is unavailable"},
+	}, failed)
+
+	_, _, err = selectorTargets(apiOp(), schema(accesscontrol.AccessList{
+		{Namespace: "prod", ResourceName: accesscontrol.All},
+	}), getter)
+	assert.Error(t, err)
+
+	targets, failed, err = selectorTargets(apiOp(), schema(accesscontrol.AccessList{
+		{Namespace: "test", ResourceName: "c"},
+	}), getter)
+	require.NoError(t, err)
+	assert.Equal(t, []deleteTarget{{namespace: "test", name: "c"}}, targets)
+	assert.Empty(t, failed)
+}
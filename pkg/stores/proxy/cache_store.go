@@ -0,0 +1,289 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/acorn-io/brent/pkg/accesscontrol"
+	"github.com/acorn-io/brent/pkg/attributes"
+	"github.com/acorn-io/brent/pkg/parse"
+	"github.com/acorn-io/brent/pkg/stores/partition"
+	types2 "github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/validation"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Informers holds one shared informer per GVR. Informers are started and stopped by the schema collection
+// through Start as schemas come and go.
+type Informers struct {
+	clientGetter ClientGetter
+	lock         sync.RWMutex
+	informers    map[schema.GroupVersionResource]cache.SharedIndexInformer
+}
+
+func NewInformers(clientGetter ClientGetter) *Informers {
+	return &Informers{
+		clientGetter: clientGetter,
+		informers:    map[schema.GroupVersionResource]cache.SharedIndexInformer{},
+	}
+}
+
+func isListWatchable(schema *types2.APISchema) bool {
+	verbs := sets.NewString(attributes.Verbs(schema)...)
+	return verbs.Has("list") && verbs.Has("watch")
+}
+
+// Start runs an informer for the schema's GVR until ctx is done.
+func (i *Informers) Start(ctx context.Context, schema *types2.APISchema) error {
+	gvr := attributes.GVR(schema)
+	if gvr.Resource == "" || !isListWatchable(schema) {
+		return nil
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if _, ok := i.informers[gvr]; ok {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	if err != nil {
+		return err
+	}
+	k8sClient, err := i.clientGetter.TableAdminClientForWatch(&types2.APIRequest{Request: req}, schema, "")
+	if err != nil {
+		return err
+	}
+
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			list, err := k8sClient.List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			tableToList(list)
+			return list, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			w, err := k8sClient.Watch(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+				if unstr, ok := event.Object.(*unstructured.Unstructured); ok {
+					rowToObject(unstr)
+				}
+				return event, true
+			}), nil
+		},
+	}, &unstructured.Unstructured{}, 0, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+
+	i.informers[gvr] = informer
+	logrus.Debugf("starting informer for %s", gvr)
+
+	go func() {
+		informer.Run(ctx.Done())
+		logrus.Debugf("stopped informer for %s", gvr)
+
+		i.lock.Lock()
+		defer i.lock.Unlock()
+		if i.informers[gvr] == informer {
+			delete(i.informers, gvr)
+		}
+	}()
+
+	return nil
+}
+
+func (i *Informers) get(schema *types2.APISchema) (cache.SharedIndexInformer, bool) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	informer, ok := i.informers[attributes.GVR(schema)]
+	if !ok || !informer.HasSynced() {
+		return nil, false
+	}
+	return informer, true
+}
+
+// NewCacheStore returns a store that serves List and ByID from the informers, filtered by the same RBAC
+// partitions as the proxy store. All other operations, and reads for schemas that have no synced informer,
+// are sent to next.
+func NewCacheStore(next types2.Store, clientGetter ClientGetter, informers *Informers, tokens *partition.ContinueTokens) types2.Store {
+	return &cacheStore{
+		Store:     next,
+		informers: informers,
+		cached: &errorStore{
+			Store: &partition.Store{
+				Partitioner: &rbacPartitioner{
+					proxyStore: &informerStore{
+						Store: &Store{
+							clientGetter: clientGetter,
+						},
+						informers: informers,
+					},
+				},
+				Tokens: tokens,
+			},
+		},
+	}
+}
+
+type cacheStore struct {
+	types2.Store
+
+	informers *Informers
+	cached    types2.Store
+}
+
+func (c *cacheStore) useCache(apiOp *types2.APIRequest, schema *types2.APISchema) bool {
+	if _, ok := c.informers.get(schema); !ok {
+		return false
+	}
+	// field selectors and explicit resource versions can only be answered by the apiserver
+	q := apiOp.Request.URL.Query()
+	return q.Get("fieldSelector") == "" && q.Get("resourceVersion") == ""
+}
+
+func (c *cacheStore) ByID(apiOp *types2.APIRequest, schema *types2.APISchema, id string) (types2.APIObject, error) {
+	if !c.useCache(apiOp, schema) {
+		return c.Store.ByID(apiOp, schema, id)
+	}
+	return c.cached.ByID(apiOp, schema, id)
+}
+
+func (c *cacheStore) List(apiOp *types2.APIRequest, schema *types2.APISchema) (types2.APIObjectList, error) {
+	if !c.useCache(apiOp, schema) {
+		return c.Store.List(apiOp, schema)
+	}
+	return c.cached.List(apiOp, schema)
+}
+
+// informerStore answers the reads of a single partition from the informer cache. The informer lists as admin
+// so every read must already be constrained by the partition or checked against the schema access.
+type informerStore struct {
+	*Store
+
+	informers *Informers
+}
+
+func (i *informerStore) ByID(apiOp *types2.APIRequest, schema *types2.APISchema, id string) (types2.APIObject, error) {
+	informer, ok := i.informers.get(schema)
+	if !ok {
+		return i.Store.ByID(apiOp, schema, id)
+	}
+
+	if !accesscontrol.GetAccessListMap(schema).Grants("get", apiOp.Namespace, id) {
+		return types2.APIObject{}, validation.NotFound
+	}
+
+	key := id
+	if apiOp.Namespace != "" {
+		key = apiOp.Namespace + "/" + id
+	}
+
+	obj, ok, err := informer.GetIndexer().GetByKey(key)
+	if err != nil {
+		return types2.APIObject{}, err
+	} else if !ok {
+		return types2.APIObject{}, validation.NotFound
+	}
+
+	return toAPI(schema, obj.(*unstructured.Unstructured).DeepCopy()), nil
+}
+
+func (i *informerStore) List(apiOp *types2.APIRequest, schema *types2.APISchema) (types2.APIObjectList, error) {
+	return i.list(apiOp, schema, nil)
+}
+
+func (i *informerStore) ByNames(apiOp *types2.APIRequest, schema *types2.APISchema, names sets.String) (types2.APIObjectList, error) {
+	if apiOp.Namespace == "*" {
+		// same as the proxy store, never list the whole cluster to find objects granted by name
+		return types2.APIObjectList{}, nil
+	}
+	if names == nil {
+		// partitions of namespaces without any grant have no names, which must match nothing and not everything
+		names = sets.String{}
+	}
+	return i.list(apiOp, schema, names)
+}
+
+func (i *informerStore) list(apiOp *types2.APIRequest, schema *types2.APISchema, names sets.String) (types2.APIObjectList, error) {
+	informer, ok := i.informers.get(schema)
+	if !ok {
+		if names == nil {
+			return i.Store.List(apiOp, schema)
+		}
+		return i.Store.ByNames(apiOp, schema, names)
+	}
+
+	opts := metav1.ListOptions{}
+	if err := decodeParams(apiOp, &opts); err != nil {
+		return types2.APIObjectList{}, err
+	}
+
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return types2.APIObjectList{}, err
+	}
+
+	filters, err := parse.Filters(apiOp.Request.URL.Query())
+	if err != nil {
+		return types2.APIObjectList{}, err
+	}
+
+	var objs []interface{}
+	if apiOp.Namespace == "" {
+		objs = informer.GetIndexer().List()
+	} else {
+		objs, err = informer.GetIndexer().ByIndex(cache.NamespaceIndex, apiOp.Namespace)
+		if err != nil {
+			return types2.APIObjectList{}, err
+		}
+	}
+
+	var items []*unstructured.Unstructured
+	for _, obj := range objs {
+		unstr, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		if names != nil && !names.Has(unstr.GetName()) {
+			continue
+		}
+		if !selector.Matches(labels.Set(unstr.GetLabels())) || !parse.MatchesAll(filters, unstr.Object) {
+			continue
+		}
+		items = append(items, unstr)
+	}
+
+	// the partition lister resumes by offset so the order must be stable between requests
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].GetNamespace() != items[j].GetNamespace() {
+			return items[i].GetNamespace() < items[j].GetNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+
+	result := types2.APIObjectList{
+		Revision: informer.LastSyncResourceVersion(),
+	}
+	for _, item := range items {
+		result.Objects = append(result.Objects, toAPI(schema, item.DeepCopy()))
+	}
+
+	return result, nil
+}
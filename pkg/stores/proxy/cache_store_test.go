@@ -0,0 +1,204 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/accesscontrol"
+	"github.com/acorn-io/brent/pkg/attributes"
+	"github.com/acorn-io/brent/pkg/stores/partition"
+	types2 "github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// adminClientGetter returns admin clients, such as the ones the informers list and watch with
+type adminClientGetter struct {
+	ClientGetter
+
+	client dynamic.Interface
+}
+
+func (a *adminClientGetter) TableAdminClientForWatch(_ *types2.APIRequest, _ *types2.APISchema, namespace string) (dynamic.ResourceInterface, error) {
+	return a.client.Resource(configMapGVR).Namespace(namespace), nil
+}
+
+func (a *adminClientGetter) AdminClient(_ *types2.APIRequest, _ *types2.APISchema, namespace string) (dynamic.ResourceInterface, error) {
+	return a.client.Resource(configMapGVR).Namespace(namespace), nil
+}
+
+func configMap(namespace, name string) runtime.Object {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+	}}
+}
+
+func configMapSchema(access accesscontrol.AccessList) *types2.APISchema {
+	schema := &types2.APISchema{Schema: &schemas.Schema{ID: "configmap"}}
+	attributes.SetVersion(schema, "v1")
+	attributes.SetResource(schema, "configmaps")
+	attributes.SetKind(schema, "ConfigMap")
+	attributes.SetNamespaced(schema, true)
+	attributes.SetVerbs(schema, []string{"get", "list", "watch"})
+	attributes.SetAccess(schema, accesscontrol.AccessListByVerb{
+		"get":  access,
+		"list": access,
+	})
+	return schema
+}
+
+func listIDs(t *testing.T, list types2.APIObjectList, err error) []string {
+	require.NoError(t, err)
+	ids := []string{}
+	for _, obj := range list.Objects {
+		ids = append(ids, obj.ID)
+	}
+	return ids
+}
+
+// TestInformerStoreAccess checks that reads served from the informer, which lists as admin, only return what the
+// access of the user grants
+func TestInformerStoreAccess(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		configMap("dev", "a"),
+		configMap("dev", "b"),
+		configMap("prod", "allowed"),
+		configMap("prod", "secret"),
+		configMap("test", "c"),
+	)
+	getter := &adminClientGetter{client: client}
+	informers := NewInformers(getter)
+	require.NoError(t, informers.Start(ctx, configMapSchema(nil)))
+	require.Eventually(t, func() bool {
+		_, ok := informers.get(configMapSchema(nil))
+		return ok
+	}, wait.ForeverTestTimeout, 10*time.Millisecond)
+
+	store := NewCacheStore(&Store{clientGetter: getter}, getter, informers, partition.NewContinueTokens(nil))
+	apiRequest := func(namespace string) *types2.APIRequest {
+		return &types2.APIRequest{
+			Namespace: namespace,
+			Request:   httptest.NewRequest(http.MethodGet, "/v1/configmaps", nil),
+		}
+	}
+
+	tests := []struct {
+		name      string
+		access    accesscontrol.AccessList
+		namespace string
+		wantList  []string
+		allowed   []string
+		denied    []string
+	}{
+		{
+			name:     "one namespace",
+			access:   accesscontrol.AccessList{{Namespace: "dev", ResourceName: accesscontrol.All}},
+			wantList: []string{"dev/a", "dev/b"},
+			allowed:  []string{"dev/a"},
+			denied:   []string{"prod/allowed", "test/c"},
+		},
+		{
+			name:      "other namespace of the request",
+			access:    accesscontrol.AccessList{{Namespace: "dev", ResourceName: accesscontrol.All}},
+			namespace: "prod",
+			wantList:  []string{},
+		},
+		{
+			name:     "resource names",
+			access:   accesscontrol.AccessList{{Namespace: "prod", ResourceName: "allowed"}},
+			wantList: []string{"prod/allowed"},
+			allowed:  []string{"prod/allowed"},
+			denied:   []string{"prod/secret", "dev/a"},
+		},
+		{
+			name:      "resource names in the namespace of the request",
+			access:    accesscontrol.AccessList{{Namespace: "prod", ResourceName: "allowed"}},
+			namespace: "prod",
+			wantList:  []string{"prod/allowed"},
+		},
+		{
+			name:     "nothing",
+			wantList: []string{},
+			denied:   []string{"dev/a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := configMapSchema(tt.access)
+
+			list, err := store.List(apiRequest(tt.namespace), schema)
+			assert.Equal(t, tt.wantList, listIDs(t, list, err))
+
+			for _, id := range tt.allowed {
+				namespace, name, _ := strings.Cut(id, "/")
+				obj, err := store.ByID(apiRequest(namespace), schema, name)
+				if assert.NoError(t, err, id) {
+					assert.Equal(t, id, obj.ID)
+				}
+			}
+			for _, id := range tt.denied {
+				namespace, name, _ := strings.Cut(id, "/")
+				_, err := store.ByID(apiRequest(namespace), schema, name)
+				assert.Error(t, err, id)
+			}
+		})
+	}
+}
+
+func TestInformerStoreByNames(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		configMap("dev", "a"),
+		configMap("prod", "a"),
+		configMap("prod", "b"),
+	)
+	getter := &adminClientGetter{client: client}
+	informers := NewInformers(getter)
+	schema := configMapSchema(nil)
+	require.NoError(t, informers.Start(ctx, schema))
+	require.Eventually(t, func() bool {
+		_, ok := informers.get(schema)
+		return ok
+	}, wait.ForeverTestTimeout, 10*time.Millisecond)
+
+	store := &informerStore{Store: &Store{clientGetter: getter}, informers: informers}
+	byNames := func(namespace string, names ...string) []string {
+		list, err := store.ByNames(&types2.APIRequest{
+			Namespace: namespace,
+			Request:   httptest.NewRequest(http.MethodGet, "/v1/configmaps", nil),
+		}, schema, sets.NewString(names...))
+		return listIDs(t, list, err)
+	}
+
+	assert.Equal(t, []string{"prod/a"}, byNames("prod", "a"))
+	assert.Equal(t, []string{}, byNames("prod", "c"))
+	assert.Equal(t, []string{}, byNames("*", "a"))
+
+	list, err := store.ByNames(&types2.APIRequest{
+		Namespace: "prod",
+		Request:   httptest.NewRequest(http.MethodGet, "/v1/configmaps", nil),
+	}, schema, nil)
+	assert.Equal(t, []string{}, listIDs(t, list, err))
+}
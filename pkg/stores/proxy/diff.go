@@ -0,0 +1,220 @@
+package proxy
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/acorn-io/brent/pkg/attributes"
+	"github.com/acorn-io/brent/pkg/parse"
+	types2 "github.com/acorn-io/brent/pkg/types"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	apitypes "k8s.io/apimachinery/pkg/types"
+)
+
+// diffIgnoredFields change on every write and would only add noise to a diff
+var diffIgnoredFields = map[string]bool{
+	"/metadata/managedFields":   true,
+	"/metadata/resourceVersion": true,
+}
+
+type change struct {
+	Op   string      `json:"op"`
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// DiffHandler handles the diff resource action. The request body is either the desired object, as for an update,
+// or a patch with one of the patch content types. The change is run as a dry run with the user's permissions and
+// the response lists how the result differs from the live object.
+func DiffHandler(clientGetter ClientGetter) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		apiOp := types2.GetAPIContext(req.Context())
+		result, err := diff(apiOp, clientGetter)
+		if err != nil {
+			apiOp.WriteError(translateError(err))
+			return
+		}
+		apiOp.WriteResponse(http.StatusOK, result)
+	})
+}
+
+func isPatch(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch apitypes.PatchType(mediaType) {
+	case apitypes.JSONPatchType, apitypes.MergePatchType, apitypes.StrategicMergePatchType, apitypes.ApplyPatchType:
+		return true
+	}
+	return false
+}
+
+func diff(apiOp *types2.APIRequest, clientGetter ClientGetter) (types2.APIObject, error) {
+	k8sClient, err := clientGetter.Client(apiOp, apiOp.Schema, apiOp.Namespace)
+	if err != nil {
+		return types2.APIObject{}, err
+	}
+
+	live, err := k8sClient.Get(apiOp.Context(), apiOp.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		live = nil
+	} else if err != nil {
+		return types2.APIObject{}, err
+	}
+
+	// the dry run creates the object when it does not exist yet
+	if live == nil {
+		err = apiOp.AccessControl.CanCreate(apiOp, apiOp.Schema)
+	} else {
+		err = apiOp.AccessControl.CanUpdate(apiOp, types2.APIObject{}, apiOp.Schema)
+	}
+	if err != nil {
+		return types2.APIObject{}, err
+	}
+
+	dryRun := []string{metav1.DryRunAll}
+
+	var result *unstructured.Unstructured
+	if contentType := apiOp.Request.Header.Get("content-type"); isPatch(contentType) {
+		bytes, err := io.ReadAll(io.LimitReader(apiOp.Request.Body, 2<<20))
+		if err != nil {
+			return types2.APIObject{}, err
+		}
+		pType := patchType(contentType)
+		bytes, err = patchBody(pType, bytes)
+		if err != nil {
+			return types2.APIObject{}, err
+		}
+		opts := metav1.PatchOptions{}
+		if err := decodeParams(apiOp, &opts); err != nil {
+			return types2.APIObject{}, err
+		}
+		opts.DryRun = dryRun
+		result, err = k8sClient.Patch(apiOp.Context(), apiOp.Name, pType, bytes, opts)
+		if err != nil {
+			return types2.APIObject{}, err
+		}
+	} else {
+		body, err := parse.ReadBody(apiOp.Request)
+		if err != nil {
+			return types2.APIObject{}, err
+		}
+		input := moveFromUnderscore(body.Data())
+		obj := &unstructured.Unstructured{Object: input}
+		obj.SetGroupVersionKind(attributes.GVK(apiOp.Schema))
+		obj.SetName(apiOp.Name)
+		if apiOp.Namespace != "" {
+			obj.SetNamespace(apiOp.Namespace)
+		}
+
+		if live == nil {
+			opts := metav1.CreateOptions{}
+			if err := decodeParams(apiOp, &opts); err != nil {
+				return types2.APIObject{}, err
+			}
+			opts.DryRun = dryRun
+			result, err = k8sClient.Create(apiOp.Context(), obj, opts)
+		} else {
+			if obj.GetResourceVersion() == "" {
+				obj.SetResourceVersion(live.GetResourceVersion())
+			}
+			opts := metav1.UpdateOptions{}
+			if err := decodeParams(apiOp, &opts); err != nil {
+				return types2.APIObject{}, err
+			}
+			opts.DryRun = dryRun
+			result, err = k8sClient.Update(apiOp.Context(), obj, opts)
+		}
+		if err != nil {
+			return types2.APIObject{}, err
+		}
+	}
+
+	var liveObject map[string]interface{}
+	if live != nil {
+		liveObject = live.Object
+	}
+
+	return types2.APIObject{
+		Type: "diff",
+		Object: map[string]interface{}{
+			"live":    liveObject,
+			"result":  result.Object,
+			"changes": diffObjects(liveObject, result.Object),
+		},
+	}, nil
+}
+
+// diffObjects returns the changes from old to new as JSON pointer paths. Maps are compared key by key, lists of
+// the same length element by element, anything else is replaced as a whole.
+func diffObjects(old, new map[string]interface{}) []change {
+	changes := []change{}
+	diffValue("", old, new, &changes)
+	return changes
+}
+
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func diffValue(path string, old, new interface{}, changes *[]change) {
+	if diffIgnoredFields[path] || reflect.DeepEqual(old, new) {
+		return
+	}
+
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if oldIsMap && newIsMap || path == "" {
+		keys := map[string]bool{}
+		for k := range oldMap {
+			keys[k] = true
+		}
+		for k := range newMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			childPath := path + "/" + escapePointer(k)
+			oldValue, inOld := oldMap[k]
+			newValue, inNew := newMap[k]
+			switch {
+			case !inOld:
+				if !diffIgnoredFields[childPath] {
+					*changes = append(*changes, change{Op: "add", Path: childPath, New: newValue})
+				}
+			case !inNew:
+				if !diffIgnoredFields[childPath] {
+					*changes = append(*changes, change{Op: "remove", Path: childPath, Old: oldValue})
+				}
+			default:
+				diffValue(childPath, oldValue, newValue, changes)
+			}
+		}
+		return
+	}
+
+	oldSlice, oldIsSlice := old.([]interface{})
+	newSlice, newIsSlice := new.([]interface{})
+	if oldIsSlice && newIsSlice && len(oldSlice) == len(newSlice) {
+		for i := range oldSlice {
+			diffValue(path+"/"+strconv.Itoa(i), oldSlice[i], newSlice[i], changes)
+		}
+		return
+	}
+
+	*changes = append(*changes, change{Op: "replace", Path: path, Old: old, New: new})
+}
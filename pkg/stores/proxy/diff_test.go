@@ -0,0 +1,122 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/acorn-io/brent/pkg/accesscontrol"
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/attributes"
+	types2 "github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer"
+	"github.com/acorn-io/schemer/validation"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestDiffAccess(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), configMap("dev", "web"))
+	getter := &userClientGetter{client: client}
+
+	// the user can create config maps but not update them
+	schema := &types2.APISchema{Schema: &schemas.Schema{
+		ID:                "configmap",
+		CollectionMethods: []string{http.MethodPost},
+	}}
+	attributes.SetVersion(schema, "v1")
+	attributes.SetKind(schema, "ConfigMap")
+	attributes.SetNamespaced(schema, true)
+
+	apiOp := func(name string) *types2.APIRequest {
+		req := httptest.NewRequest(http.MethodPost, "/v1/configmaps/dev/"+name+"?action=diff", strings.NewReader(`{"data":{"key":"value"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		return &types2.APIRequest{
+			Name:          name,
+			Namespace:     "dev",
+			Schema:        schema,
+			AccessControl: &accesscontrol.SchemaBasedAccess{},
+			Request:       req,
+		}
+	}
+
+	_, err := diff(apiOp("new"), getter)
+	assert.NoError(t, err)
+
+	_, err = diff(apiOp("web"), getter)
+	if apiErr, ok := err.(*apierror.APIError); assert.True(t, ok, "%v", err) {
+		assert.Equal(t, validation.PermissionDenied, apiErr.Code)
+	}
+}
+
+func TestDiffObjects(t *testing.T) {
+	tests := []struct {
+		name string
+		old  map[string]interface{}
+		new  map[string]interface{}
+		want []change
+	}{
+		{
+			name: "no changes",
+			old:  map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(1)}},
+			new:  map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(1)}},
+			want: []change{},
+		},
+		{
+			name: "replace, add and remove",
+			old: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"app": "web", "tier": "frontend"},
+				},
+				"spec": map[string]interface{}{"replicas": int64(1)},
+			},
+			new: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"app": "web", "app.kubernetes.io/name": "web"},
+				},
+				"spec": map[string]interface{}{"replicas": int64(3)},
+			},
+			want: []change{
+				{Op: "add", Path: "/metadata/labels/app.kubernetes.io~1name", New: "web"},
+				{Op: "remove", Path: "/metadata/labels/tier", Old: "frontend"},
+				{Op: "replace", Path: "/spec/replicas", Old: int64(1), New: int64(3)},
+			},
+		},
+		{
+			name: "lists of the same length are compared by element",
+			old: map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"image": "web:1"}},
+			},
+			new: map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"image": "web:2"}},
+			},
+			want: []change{
+				{Op: "replace", Path: "/containers/0/image", Old: "web:1", New: "web:2"},
+			},
+		},
+		{
+			name: "resource version and managed fields are ignored",
+			old: map[string]interface{}{
+				"metadata": map[string]interface{}{"resourceVersion": "1", "managedFields": []interface{}{}},
+			},
+			new: map[string]interface{}{
+				"metadata": map[string]interface{}{"resourceVersion": "2", "managedFields": []interface{}{"x"}},
+			},
+			want: []change{},
+		},
+		{
+			name: "new object",
+			new:  map[string]interface{}{"kind": "Pod"},
+			want: []change{
+				{Op: "add", Path: "/kind", New: "Pod"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, diffObjects(tt.old, tt.new))
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package proxy
 
 import (
+	"net/http"
+	"slices"
+	"strings"
+
 	"github.com/acorn-io/brent/pkg/apierror"
 	types2 "github.com/acorn-io/brent/pkg/types"
 	"github.com/acorn-io/schemer/validation"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type errorStore struct {
@@ -44,13 +49,77 @@ func (e *errorStore) Watch(apiOp *types2.APIRequest, schema *types2.APISchema, w
 	return data, translateError(err)
 }
 
+// TranslateError converts the status errors of the apiserver to the API errors written to the client, for handlers
+// that talk to the apiserver outside of the stores.
+func TranslateError(err error) error {
+	return translateError(err)
+}
+
 func translateError(err error) error {
 	if apiError, ok := err.(errors.APIStatus); ok {
 		status := apiError.Status()
-		return apierror.NewAPIError(validation.ErrorCode{
-			Status: int(status.Code),
-			Code:   string(status.Reason),
-		}, status.Message)
+		result := &apierror.APIError{
+			Code: validation.ErrorCode{
+				Status: int(status.Code),
+				Code:   string(status.Reason),
+			},
+			Message: status.Message,
+		}
+		if conflicts := applyConflicts(status); conflicts != nil {
+			result.Details = conflicts
+		}
+		return result
 	}
 	return err
 }
+
+type applyConflict struct {
+	Manager string `json:"manager"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// applyConflicts returns the fields a server-side apply could not take ownership of and the managers that own
+// them, or nil if status is not an apply conflict.
+func applyConflicts(status metav1.Status) map[string]interface{} {
+	if status.Code != http.StatusConflict || status.Details == nil {
+		return nil
+	}
+
+	var (
+		conflicts []applyConflict
+		managers  []string
+	)
+	for _, cause := range status.Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		manager := conflictManager(cause.Message)
+		if manager != "" && !slices.Contains(managers, manager) {
+			managers = append(managers, manager)
+		}
+		conflicts = append(conflicts, applyConflict{
+			Manager: manager,
+			Field:   cause.Field,
+			Message: cause.Message,
+		})
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"managers":  managers,
+		"conflicts": conflicts,
+	}
+}
+
+// conflictManager parses the manager out of a conflict message such as: conflict with "kubectl" using apps/v1
+func conflictManager(message string) string {
+	_, rest, ok := strings.Cut(message, `conflict with "`)
+	if !ok {
+		return ""
+	}
+	manager, _, _ := strings.Cut(rest, `"`)
+	return manager
+}
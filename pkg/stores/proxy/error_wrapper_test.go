@@ -0,0 +1,65 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestTranslateError(t *testing.T) {
+	conflict := errors.NewApplyConflict([]metav1.StatusCause{
+		{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: `conflict with "kubectl" using apps/v1`,
+			Field:   ".spec.replicas",
+		},
+		{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: `conflict with "helm" with subresource "scale" using apps/v1`,
+			Field:   ".spec.template.spec.containers[name=\"web\"].image",
+		},
+	}, "Apply failed with 2 conflicts")
+
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantDetails interface{}
+	}{
+		{
+			name:       "apply conflict",
+			err:        conflict,
+			wantStatus: 409,
+			wantDetails: map[string]interface{}{
+				"managers": []string{"kubectl", "helm"},
+				"conflicts": []applyConflict{
+					{Manager: "kubectl", Field: ".spec.replicas", Message: `conflict with "kubectl" using apps/v1`},
+					{Manager: "helm", Field: ".spec.template.spec.containers[name=\"web\"].image", Message: `conflict with "helm" with subresource "scale" using apps/v1`},
+				},
+			},
+		},
+		{
+			name:       "optimistic lock conflict has no details",
+			err:        errors.NewConflict(schema.GroupResource{Resource: "pods"}, "web", nil),
+			wantStatus: 409,
+		},
+		{
+			name:       "not found",
+			err:        errors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web"),
+			wantStatus: 404,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr, ok := translateError(tt.err).(*apierror.APIError)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.wantStatus, apiErr.Code.Status)
+				assert.Equal(t, tt.wantDetails, apiErr.Details)
+			}
+		})
+	}
+}
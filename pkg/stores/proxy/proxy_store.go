@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"os"
 	"reflect"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/acorn-io/brent/pkg/accesscontrol"
 	"github.com/acorn-io/brent/pkg/attributes"
+	"github.com/acorn-io/brent/pkg/parse"
 	"github.com/acorn-io/brent/pkg/stores/partition"
 	types2 "github.com/acorn-io/brent/pkg/types"
 	"github.com/acorn-io/schemer/data"
@@ -25,9 +27,11 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	apitypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 const watchTimeoutEnv = "CATTLE_WATCH_TIMEOUT_SECONDS"
@@ -46,6 +50,7 @@ type ClientGetter interface {
 	IsImpersonating() bool
 	K8sInterface(ctx *types2.APIRequest) (kubernetes.Interface, error)
 	AdminK8sInterface() (kubernetes.Interface, error)
+	RESTConfig(ctx *types2.APIRequest) (*rest.Config, error)
 	Client(ctx *types2.APIRequest, schema *types2.APISchema, namespace string) (dynamic.ResourceInterface, error)
 	DynamicClient(ctx *types2.APIRequest) (dynamic.Interface, error)
 	AdminClient(ctx *types2.APIRequest, schema *types2.APISchema, namespace string) (dynamic.ResourceInterface, error)
@@ -59,7 +64,7 @@ type Store struct {
 	clientGetter ClientGetter
 }
 
-func NewProxyStore(clientGetter ClientGetter, lookup accesscontrol.AccessSetLookup) types2.Store {
+func NewProxyStore(clientGetter ClientGetter, lookup accesscontrol.AccessSetLookup, tokens *partition.ContinueTokens) types2.Store {
 	return &errorStore{
 		Store: &WatchRefresh{
 			Store: &partition.Store{
@@ -68,6 +73,7 @@ func NewProxyStore(clientGetter ClientGetter, lookup accesscontrol.AccessSetLook
 						clientGetter: clientGetter,
 					},
 				},
+				Tokens: tokens,
 			},
 			asl: lookup,
 		},
@@ -251,23 +257,41 @@ func (s *Store) list(apiOp *types2.APIRequest, schema *types2.APISchema, k8sClie
 		return types2.APIObjectList{}, nil
 	}
 
-	resultList, err := k8sClient.List(apiOp.Context(), opts)
+	filters, err := parse.Filters(apiOp.Request.URL.Query())
 	if err != nil {
 		return types2.APIObjectList{}, err
 	}
 
-	tableToList(resultList)
+	var result types2.APIObjectList
+	limit := opts.Limit
+	for {
+		resultList, err := k8sClient.List(apiOp.Context(), opts)
+		if err != nil {
+			return types2.APIObjectList{}, err
+		}
 
-	result := types2.APIObjectList{
-		Revision: resultList.GetResourceVersion(),
-		Continue: resultList.GetContinue(),
-	}
+		tableToList(resultList)
 
-	for i := range resultList.Items {
-		result.Objects = append(result.Objects, toAPI(schema, &resultList.Items[i]))
-	}
+		if result.Revision == "" {
+			result.Revision = resultList.GetResourceVersion()
+		}
+		result.Continue = resultList.GetContinue()
 
-	return result, nil
+		for i := range resultList.Items {
+			if !parse.MatchesAll(filters, resultList.Items[i].Object) {
+				continue
+			}
+			result.Objects = append(result.Objects, toAPI(schema, &resultList.Items[i]))
+		}
+
+		// filters drop objects of the page, keep listing so a page is only short when it is the last one. The next
+		// pages only ask for what is missing so the page never has more than limit objects.
+		if limit <= 0 || len(filters) == 0 || result.Continue == "" || int64(len(result.Objects)) >= limit {
+			return result, nil
+		}
+		opts.Continue = result.Continue
+		opts.Limit = limit - int64(len(result.Objects))
+	}
 }
 
 func returnErr(err error, c chan types2.APIEvent) {
@@ -294,10 +318,12 @@ func (s *Store) listAndWatch(apiOp *types2.APIRequest, k8sClient dynamic.Resourc
 		}
 	}
 	watcher, err := k8sClient.Watch(apiOp.Context(), metav1.ListOptions{
-		Watch:           true,
-		TimeoutSeconds:  &timeout,
-		ResourceVersion: rev,
-		LabelSelector:   w.Selector,
+		Watch:               true,
+		TimeoutSeconds:      &timeout,
+		ResourceVersion:     rev,
+		LabelSelector:       w.Selector,
+		FieldSelector:       w.FieldSelector,
+		AllowWatchBookmarks: true,
 	})
 	if err != nil {
 		returnErr(fmt.Errorf("stopping watch for %s: %w", schema.ID, err), result)
@@ -324,6 +350,10 @@ func (s *Store) listAndWatch(apiOp *types2.APIRequest, k8sClient dynamic.Resourc
 				}
 				continue
 			}
+			if event.Type == watch.Bookmark {
+				result <- toBookmarkEvent(event.Object)
+				continue
+			}
 			result <- s.toAPIEvent(apiOp, schema, event.Type, event.Object)
 		}
 		return fmt.Errorf("closed")
@@ -347,7 +377,7 @@ func (s *Store) WatchNames(apiOp *types2.APIRequest, schema *types2.APISchema, w
 	go func() {
 		defer close(result)
 		for item := range c {
-			if item.Error == nil && names.Has(item.Object.Name()) {
+			if item.Name == types2.BookmarkAPIEvent || item.Error == nil && names.Has(item.Object.Name()) {
 				result <- item
 			}
 		}
@@ -374,6 +404,16 @@ func (s *Store) watch(apiOp *types2.APIRequest, schema *types2.APISchema, w type
 	return result, nil
 }
 
+func toBookmarkEvent(obj runtime.Object) types2.APIEvent {
+	event := types2.APIEvent{
+		Name: types2.BookmarkAPIEvent,
+	}
+	if m, err := meta.Accessor(obj); err == nil {
+		event.Revision = m.GetResourceVersion()
+	}
+	return event
+}
+
 func (s *Store) toAPIEvent(apiOp *types2.APIRequest, schema *types2.APISchema, et watch.EventType, obj runtime.Object) types2.APIEvent {
 	name := types2.ChangeAPIEvent
 	switch et {
@@ -447,7 +487,11 @@ func (s *Store) Update(apiOp *types2.APIRequest, schema *types2.APISchema, param
 		input = params.Data()
 	)
 
+	// the body of a patch is not parsed, so the namespace usually only comes from the request
 	ns := types2.Namespace(input)
+	if ns == "" {
+		ns = apiOp.Namespace
+	}
 	k8sClient, err := s.clientGetter.TableClient(apiOp, schema, ns)
 	if err != nil {
 		return types2.APIObject{}, err
@@ -459,26 +503,16 @@ func (s *Store) Update(apiOp *types2.APIRequest, schema *types2.APISchema, param
 			return types2.APIObject{}, err
 		}
 
-		pType := apitypes.StrategicMergePatchType
-		if apiOp.Request.Header.Get("content-type") == string(apitypes.JSONPatchType) {
-			pType = apitypes.JSONPatchType
-		}
+		pType := patchType(apiOp.Request.Header.Get("content-type"))
 
 		opts := metav1.PatchOptions{}
 		if err := decodeParams(apiOp, &opts); err != nil {
 			return types2.APIObject{}, err
 		}
 
-		if pType == apitypes.StrategicMergePatchType {
-			data := map[string]interface{}{}
-			if err := json.Unmarshal(bytes, &data); err != nil {
-				return types2.APIObject{}, err
-			}
-			data = moveFromUnderscore(data)
-			bytes, err = json.Marshal(data)
-			if err != nil {
-				return types2.APIObject{}, err
-			}
+		bytes, err = patchBody(pType, bytes)
+		if err != nil {
+			return types2.APIObject{}, err
 		}
 
 		resp, err := k8sClient.Patch(apiOp.Context(), id, pType, bytes, opts)
@@ -499,7 +533,7 @@ func (s *Store) Update(apiOp *types2.APIRequest, schema *types2.APISchema, param
 		return types2.APIObject{}, err
 	}
 
-	resp, err := k8sClient.Update(apiOp.Context(), &unstructured.Unstructured{Object: moveFromUnderscore(input)}, metav1.UpdateOptions{})
+	resp, err := k8sClient.Update(apiOp.Context(), &unstructured.Unstructured{Object: moveFromUnderscore(input)}, opts)
 	if err != nil {
 		return types2.APIObject{}, err
 	}
@@ -508,10 +542,45 @@ func (s *Store) Update(apiOp *types2.APIRequest, schema *types2.APISchema, param
 	return toAPI(schema, resp), nil
 }
 
+// patchBody converts the fields the API moved to underscore names back, JSON patches are passed as is.
+func patchBody(pType apitypes.PatchType, bytes []byte) ([]byte, error) {
+	if pType == apitypes.JSONPatchType {
+		return bytes, nil
+	}
+
+	var err error
+	if pType == apitypes.ApplyPatchType {
+		// apply configurations may be YAML, convert them so the same underscore handling applies
+		bytes, err = yaml.ToJSON(bytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data := map[string]interface{}{}
+	if err := json.Unmarshal(bytes, &data); err != nil {
+		return nil, err
+	}
+	return json.Marshal(moveFromUnderscore(data))
+}
+
+// patchType maps the content type of a PATCH request to the patch type, defaulting to strategic merge.
+func patchType(contentType string) apitypes.PatchType {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return apitypes.StrategicMergePatchType
+	}
+	switch apitypes.PatchType(mediaType) {
+	case apitypes.JSONPatchType, apitypes.MergePatchType, apitypes.ApplyPatchType:
+		return apitypes.PatchType(mediaType)
+	}
+	return apitypes.StrategicMergePatchType
+}
+
 func (s *Store) Delete(apiOp *types2.APIRequest, schema *types2.APISchema, id string) (types2.APIObject, error) {
 	opts := metav1.DeleteOptions{}
 	if err := decodeParams(apiOp, &opts); err != nil {
-		return types2.APIObject{}, nil
+		return types2.APIObject{}, err
 	}
 
 	k8sClient, err := s.clientGetter.TableClient(apiOp, schema, apiOp.Namespace)
@@ -0,0 +1,132 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	types2 "github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// namespaceClientGetter returns clients of a fake dynamic client and records the namespace they are for
+type namespaceClientGetter struct {
+	ClientGetter
+
+	client    dynamic.Interface
+	namespace string
+}
+
+func (n *namespaceClientGetter) TableClient(_ *types2.APIRequest, _ *types2.APISchema, namespace string) (dynamic.ResourceInterface, error) {
+	n.namespace = namespace
+	return n.client.Resource(configMapGVR).Namespace(namespace), nil
+}
+
+func TestUpdatePatchNamespace(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      "web",
+			"namespace": "dev",
+		},
+	}})
+	getter := &namespaceClientGetter{client: client}
+	store := &Store{clientGetter: getter}
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/configmaps/dev/web", strings.NewReader(`{"data":{"key":"value"}}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	apiOp := &types2.APIRequest{
+		Method:    http.MethodPatch,
+		Namespace: "dev",
+		Request:   req,
+	}
+	schema := &types2.APISchema{Schema: &schemas.Schema{ID: "configmap"}}
+
+	obj, err := store.Update(apiOp, schema, types2.APIObject{}, "web")
+	require.NoError(t, err)
+	assert.Equal(t, "dev", getter.namespace)
+	assert.Equal(t, "dev/web", obj.ID)
+	assert.Equal(t, "value", obj.Data().String("data", "key"))
+}
+
+// pagingClient lists its objects in pages of the requested limit, the fake dynamic client ignores limit and continue
+type pagingClient struct {
+	dynamic.ResourceInterface
+
+	items []unstructured.Unstructured
+	lists int
+}
+
+func (p *pagingClient) List(_ context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	p.lists++
+	start := 0
+	if opts.Continue != "" {
+		start, _ = strconv.Atoi(opts.Continue)
+	}
+	end := len(p.items)
+	if opts.Limit > 0 && start+int(opts.Limit) < end {
+		end = start + int(opts.Limit)
+	}
+	list := &unstructured.UnstructuredList{Items: p.items[start:end]}
+	list.SetResourceVersion("1")
+	if end < len(p.items) {
+		list.SetContinue(strconv.Itoa(end))
+	}
+	return list, nil
+}
+
+func TestListFilterFillsPage(t *testing.T) {
+	client := &pagingClient{}
+	for i, team := range []string{"a", "b", "b", "b", "a", "b", "a", "a"} {
+		client.items = append(client.items, unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": strconv.Itoa(i), "namespace": "dev"},
+			"team":     team,
+		}})
+	}
+	store := &Store{}
+	schema := &types2.APISchema{Schema: &schemas.Schema{ID: "configmap"}}
+
+	list := func(query string) types2.APIObjectList {
+		result, err := store.list(&types2.APIRequest{
+			Request: httptest.NewRequest(http.MethodGet, "/v1/configmaps?"+query, nil),
+		}, schema, client)
+		require.NoError(t, err)
+		return result
+	}
+	ids := func(list types2.APIObjectList) []string {
+		var result []string
+		for _, obj := range list.Objects {
+			result = append(result, obj.ID)
+		}
+		return result
+	}
+
+	// the first page of two objects has a single match, the next pages fill it
+	first := list("limit=2&filter=team=a")
+	assert.Equal(t, []string{"dev/0", "dev/4"}, ids(first))
+	assert.Equal(t, "5", first.Continue)
+	assert.Equal(t, "1", first.Revision)
+
+	second := list("limit=2&filter=team=a&continue=" + first.Continue)
+	assert.Equal(t, []string{"dev/6", "dev/7"}, ids(second))
+	assert.Empty(t, second.Continue)
+
+	// without filters a single page is listed
+	client.lists = 0
+	assert.Equal(t, []string{"dev/0", "dev/1"}, ids(list("limit=2")))
+	assert.Equal(t, 1, client.lists)
+}
@@ -44,8 +44,15 @@ func (p Partition) Name() string {
 	return p.Namespace
 }
 
+// partitionStore is the store a single partition reads from
+type partitionStore interface {
+	types2.Store
+	ByNames(apiOp *types2.APIRequest, schema *types2.APISchema, names sets.String) (types2.APIObjectList, error)
+	WatchNames(apiOp *types2.APIRequest, schema *types2.APISchema, w types2.WatchRequest, names sets.String) (chan types2.APIEvent, error)
+}
+
 type rbacPartitioner struct {
-	proxyStore *Store
+	proxyStore partitionStore
 }
 
 func (p *rbacPartitioner) Lookup(apiOp *types2.APIRequest, schema *types2.APISchema, verb, id string) (partition.Partition, error) {
@@ -98,38 +105,38 @@ func (p *rbacPartitioner) All(apiOp *types2.APIRequest, schema *types2.APISchema
 
 func (p *rbacPartitioner) Store(apiOp *types2.APIRequest, partition partition.Partition) (types2.Store, error) {
 	return &byNameOrNamespaceStore{
-		Store:     p.proxyStore,
-		partition: partition.(Partition),
+		partitionStore: p.proxyStore,
+		partition:      partition.(Partition),
 	}, nil
 }
 
 type byNameOrNamespaceStore struct {
-	*Store
+	partitionStore
 	partition Partition
 }
 
 func (b *byNameOrNamespaceStore) List(apiOp *types2.APIRequest, schema *types2.APISchema) (types2.APIObjectList, error) {
 	if b.partition.Passthrough {
-		return b.Store.List(apiOp, schema)
+		return b.partitionStore.List(apiOp, schema)
 	}
 
 	apiOp.Namespace = b.partition.Namespace
 	if b.partition.All {
-		return b.Store.List(apiOp, schema)
+		return b.partitionStore.List(apiOp, schema)
 	}
-	return b.Store.ByNames(apiOp, schema, b.partition.Names)
+	return b.partitionStore.ByNames(apiOp, schema, b.partition.Names)
 }
 
 func (b *byNameOrNamespaceStore) Watch(apiOp *types2.APIRequest, schema *types2.APISchema, wr types2.WatchRequest) (chan types2.APIEvent, error) {
 	if b.partition.Passthrough {
-		return b.Store.Watch(apiOp, schema, wr)
+		return b.partitionStore.Watch(apiOp, schema, wr)
 	}
 
 	apiOp.Namespace = b.partition.Namespace
 	if b.partition.All {
-		return b.Store.Watch(apiOp, schema, wr)
+		return b.partitionStore.Watch(apiOp, schema, wr)
 	}
-	return b.Store.WatchNames(apiOp, schema, wr, b.partition.Names)
+	return b.partitionStore.WatchNames(apiOp, schema, wr, b.partition.Names)
 }
 
 func isPassthrough(apiOp *types2.APIRequest, schema *types2.APISchema, verb string) ([]partition.Partition, bool) {
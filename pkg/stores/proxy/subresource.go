@@ -0,0 +1,111 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/parse"
+	types2 "github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// LinkedSubresources are the subresources served as links on the schemas whose discovery advertises them
+var LinkedSubresources = []string{"status", "scale"}
+
+// SubresourceHandler serves a subresource link. GET reads the subresource, PUT and PATCH write it in the same way
+// Update writes the resource. The link handlers run after the schema's get or update access check.
+func SubresourceHandler(clientGetter ClientGetter, subresource string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		apiOp := types2.GetAPIContext(req.Context())
+		resp, err := handleSubresource(apiOp, clientGetter, subresource)
+		if err != nil {
+			apiOp.WriteError(translateError(err))
+			return
+		}
+		if subresource == "scale" {
+			// a Scale is not an object of the schema, so it is written as the autoscaling/v1 Scale the apiserver
+			// returned instead of being formatted as the parent
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(rw).Encode(resp.Object)
+			return
+		}
+		apiOp.WriteResponse(http.StatusOK, toAPI(apiOp.Schema, resp))
+	})
+}
+
+func handleSubresource(apiOp *types2.APIRequest, clientGetter ClientGetter, subresource string) (*unstructured.Unstructured, error) {
+	k8sClient, err := clientGetter.Client(apiOp, apiOp.Schema, apiOp.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *unstructured.Unstructured
+	switch apiOp.Method {
+	case http.MethodGet:
+		opts := metav1.GetOptions{}
+		if err := decodeParams(apiOp, &opts); err != nil {
+			return nil, err
+		}
+		resp, err = k8sClient.Get(apiOp.Context(), apiOp.Name, opts, subresource)
+	case http.MethodPatch:
+		bytes, err := io.ReadAll(io.LimitReader(apiOp.Request.Body, 2<<20))
+		if err != nil {
+			return nil, err
+		}
+
+		pType := patchType(apiOp.Request.Header.Get("content-type"))
+		bytes, err = patchBody(pType, bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		opts := metav1.PatchOptions{}
+		if err := decodeParams(apiOp, &opts); err != nil {
+			return nil, err
+		}
+		resp, err = k8sClient.Patch(apiOp.Context(), apiOp.Name, pType, bytes, opts, subresource)
+		if err != nil {
+			return nil, err
+		}
+	case http.MethodPut:
+		body, err := parse.ReadBody(apiOp.Request)
+		if err != nil {
+			return nil, err
+		}
+
+		obj := &unstructured.Unstructured{Object: moveFromUnderscore(body.Data())}
+		if obj.GetName() == "" {
+			obj.SetName(apiOp.Name)
+		}
+		if obj.GetNamespace() == "" && apiOp.Namespace != "" {
+			obj.SetNamespace(apiOp.Namespace)
+		}
+		if obj.GetName() != apiOp.Name {
+			return nil, apierror.NewAPIError(validation.InvalidBodyContent,
+				fmt.Sprintf("metadata.name %s does not match %s", obj.GetName(), apiOp.Name))
+		}
+
+		opts := metav1.UpdateOptions{}
+		if err := decodeParams(apiOp, &opts); err != nil {
+			return nil, err
+		}
+		resp, err = k8sClient.Update(apiOp.Context(), obj, opts, subresource)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, apierror.NewAPIError(validation.MethodNotAllowed,
+			fmt.Sprintf("Method %s not supported for %s", apiOp.Method, subresource))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -14,7 +14,7 @@ type Converter struct {
 }
 
 func MarshallObject(apiOp *types.APIRequest, getter SchemasGetter, event types.APIEvent) types.APIEvent {
-	if event.Error != nil {
+	if event.Error != nil || event.Name == types.BookmarkAPIEvent {
 		return event
 	}
 
@@ -22,34 +22,39 @@ type Subscribe struct {
 	Namespace       string `json:"namespace,omitempty"`
 	ID              string `json:"id,omitempty"`
 	Selector        string `json:"selector,omitempty"`
+	FieldSelector   string `json:"fieldSelector,omitempty"`
 }
 
 func (s *Subscribe) key() string {
-	return s.ResourceType + "/" + s.Namespace + "/" + s.ID + "/" + s.Selector
+	return s.ResourceType + "/" + s.Namespace + "/" + s.ID + "/" + s.Selector + "/" + s.FieldSelector
 }
 
-func NewHandler(getter SchemasGetter, serverVersion string) types.RequestListHandler {
+func NewHandler(getter SchemasGetter, serverVersion string, options Options) types.RequestListHandler {
 	return func(apiOp *types.APIRequest) (types.APIObjectList, error) {
-		return Handler(apiOp, getter, serverVersion)
+		return Handler(apiOp, getter, serverVersion, options)
 	}
 }
 
-func Handler(apiOp *types.APIRequest, getter SchemasGetter, serverVersion string) (types.APIObjectList, error) {
-	err := handler(apiOp, getter, serverVersion)
+func Handler(apiOp *types.APIRequest, getter SchemasGetter, serverVersion string, options Options) (types.APIObjectList, error) {
+	err := handler(apiOp, getter, serverVersion, options)
 	if err != nil {
 		logrus.Errorf("Error during subscribe %v", err)
 	}
 	return types.APIObjectList{}, validation.ErrComplete
 }
 
-func handler(apiOp *types.APIRequest, getter SchemasGetter, serverVersion string) error {
+func handler(apiOp *types.APIRequest, getter SchemasGetter, serverVersion string, options Options) error {
+	if isEventStream(apiOp.Request) {
+		return sseHandler(apiOp, getter, serverVersion, options)
+	}
+
 	c, err := Upgrader.Upgrade(apiOp.Response, apiOp.Request, nil)
 	if err != nil {
 		return err
 	}
 	defer c.Close()
 
-	watches := NewWatchSession(apiOp, getter)
+	watches := NewWatchSession(apiOp, getter, options)
 	defer watches.Close()
 
 	events := watches.Watch(c)
@@ -85,7 +90,7 @@ func handler(apiOp *types.APIRequest, getter SchemasGetter, serverVersion string
 	}
 }
 
-func writeData(apiOp *types.APIRequest, getter SchemasGetter, c *websocket.Conn, event types.APIEvent) error {
+func toWireEvent(apiOp *types.APIRequest, getter SchemasGetter, event types.APIEvent) types.APIEvent {
 	event = MarshallObject(apiOp, getter, event)
 	if event.Error != nil {
 		event.Name = "resource.error"
@@ -93,6 +98,11 @@ func writeData(apiOp *types.APIRequest, getter SchemasGetter, c *websocket.Conn,
 			"error": event.Error.Error(),
 		}
 	}
+	return event
+}
+
+func writeData(apiOp *types.APIRequest, getter SchemasGetter, c *websocket.Conn, event types.APIEvent) error {
+	event = toWireEvent(apiOp, getter, event)
 
 	messageWriter, err := c.NextWriter(websocket.TextMessage)
 	if err != nil {
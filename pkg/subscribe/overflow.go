@@ -0,0 +1,131 @@
+package subscribe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OverflowPolicy decides what happens to a subscription when the client does not read events as fast as they
+// are produced and the session buffer is full.
+type OverflowPolicy string
+
+const (
+	// OverflowDisconnect stops the subscription and sends a resource.overflow event
+	OverflowDisconnect OverflowPolicy = "disconnect"
+	// OverflowCoalesce queues events per subscription and merges events for the same object, only the latest
+	// state of an object is sent. The subscription is disconnected if the queue still fills up.
+	OverflowCoalesce OverflowPolicy = "coalesce"
+	// OverflowBlock waits up to BlockTimeout for the client to catch up before disconnecting
+	OverflowBlock OverflowPolicy = "block"
+
+	defaultBufferSize   = 100
+	defaultBlockTimeout = 10 * time.Second
+)
+
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch OverflowPolicy(s) {
+	case "", OverflowDisconnect:
+		return OverflowDisconnect, nil
+	case OverflowCoalesce, OverflowBlock:
+		return OverflowPolicy(s), nil
+	}
+	return "", fmt.Errorf("invalid overflow policy %s, must be disconnect, coalesce or block", s)
+}
+
+type Options struct {
+	// BufferSize is the number of events queued per session, and per subscription when coalescing
+	BufferSize int
+	// Overflow is the policy applied when the buffer is full, defaults to OverflowDisconnect
+	Overflow OverflowPolicy
+	// BlockTimeout is how long OverflowBlock waits for the client
+	BlockTimeout time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.BufferSize <= 0 {
+		o.BufferSize = defaultBufferSize
+	}
+	if o.Overflow == "" {
+		o.Overflow = OverflowDisconnect
+	}
+	if o.BlockTimeout <= 0 {
+		o.BlockTimeout = defaultBlockTimeout
+	}
+	return o
+}
+
+var overflows = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "brent_subscribe_overflows_total",
+	Help: "Times a websocket client fell behind by what the overflow policy did: disconnected, coalesced, blocked or block_timeout",
+}, []string{"result"})
+
+func init() {
+	prometheus.MustRegister(overflows)
+}
+
+// coalesceQueue is a bounded queue of events in which a new event for an object that is already queued replaces
+// the queued event instead of being appended.
+type coalesceQueue struct {
+	limit  int
+	events []types.APIEvent
+}
+
+func eventKey(event types.APIEvent) string {
+	if event.Name == types.BookmarkAPIEvent {
+		return event.Name
+	}
+	if event.Error != nil || event.Object.ID == "" {
+		return ""
+	}
+	return event.Object.Type + "/" + event.Object.ID
+}
+
+func (q *coalesceQueue) len() int {
+	return len(q.events)
+}
+
+func (q *coalesceQueue) peek() types.APIEvent {
+	return q.events[0]
+}
+
+func (q *coalesceQueue) pop() {
+	q.events = q.events[1:]
+}
+
+// push adds event to the queue and returns false if the queue is full.
+func (q *coalesceQueue) push(event types.APIEvent) bool {
+	key := eventKey(event)
+	if key != "" {
+		for i, queued := range q.events {
+			if eventKey(queued) != key {
+				continue
+			}
+			overflows.WithLabelValues("coalesced").Inc()
+			q.events = append(q.events[:i], q.events[i+1:]...)
+			switch {
+			case event.Name == types.BookmarkAPIEvent:
+				// a bookmark must follow every event it covers, so it always moves to the end
+			case queued.Name == types.CreateAPIEvent && event.Name == types.RemoveAPIEvent:
+				// the client never saw the object
+				return true
+			case queued.Name == types.CreateAPIEvent:
+				event.Name = types.CreateAPIEvent
+			}
+			if event.Name != types.BookmarkAPIEvent {
+				// keep the position of the queued event so events for different objects stay in order
+				q.events = append(q.events[:i], append([]types.APIEvent{event}, q.events[i:]...)...)
+				return true
+			}
+			break
+		}
+	}
+
+	if len(q.events) >= q.limit {
+		return false
+	}
+	q.events = append(q.events, event)
+	return true
+}
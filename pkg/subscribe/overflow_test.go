@@ -0,0 +1,109 @@
+package subscribe
+
+import (
+	"testing"
+
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoalesceQueue(t *testing.T) {
+	event := func(name, id, rev string) types.APIEvent {
+		if name == types.BookmarkAPIEvent {
+			return types.APIEvent{Name: name, Revision: rev}
+		}
+		return types.APIEvent{
+			Name:     name,
+			Revision: rev,
+			Object: types.APIObject{
+				Type: "pod",
+				ID:   id,
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		limit  int
+		events []types.APIEvent
+		want   []types.APIEvent
+		full   bool
+	}{
+		{
+			name:  "changes to the same object keep the latest in place",
+			limit: 10,
+			events: []types.APIEvent{
+				event(types.ChangeAPIEvent, "a", "1"),
+				event(types.ChangeAPIEvent, "b", "2"),
+				event(types.ChangeAPIEvent, "a", "3"),
+			},
+			want: []types.APIEvent{
+				event(types.ChangeAPIEvent, "a", "3"),
+				event(types.ChangeAPIEvent, "b", "2"),
+			},
+		},
+		{
+			name:  "create followed by change stays a create",
+			limit: 10,
+			events: []types.APIEvent{
+				event(types.CreateAPIEvent, "a", "1"),
+				event(types.ChangeAPIEvent, "a", "2"),
+			},
+			want: []types.APIEvent{
+				event(types.CreateAPIEvent, "a", "2"),
+			},
+		},
+		{
+			name:  "create followed by remove is dropped",
+			limit: 10,
+			events: []types.APIEvent{
+				event(types.CreateAPIEvent, "a", "1"),
+				event(types.ChangeAPIEvent, "b", "2"),
+				event(types.RemoveAPIEvent, "a", "3"),
+			},
+			want: []types.APIEvent{
+				event(types.ChangeAPIEvent, "b", "2"),
+			},
+		},
+		{
+			name:  "bookmark moves behind the events it covers",
+			limit: 10,
+			events: []types.APIEvent{
+				event(types.BookmarkAPIEvent, "", "1"),
+				event(types.ChangeAPIEvent, "a", "2"),
+				event(types.BookmarkAPIEvent, "", "3"),
+			},
+			want: []types.APIEvent{
+				event(types.ChangeAPIEvent, "a", "2"),
+				event(types.BookmarkAPIEvent, "", "3"),
+			},
+		},
+		{
+			name:  "full with distinct objects",
+			limit: 2,
+			events: []types.APIEvent{
+				event(types.ChangeAPIEvent, "a", "1"),
+				event(types.ChangeAPIEvent, "b", "2"),
+				event(types.ChangeAPIEvent, "c", "3"),
+			},
+			want: []types.APIEvent{
+				event(types.ChangeAPIEvent, "a", "1"),
+				event(types.ChangeAPIEvent, "b", "2"),
+			},
+			full: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := coalesceQueue{limit: tt.limit}
+			full := false
+			for _, e := range tt.events {
+				if !q.push(e) {
+					full = true
+				}
+			}
+			assert.Equal(t, tt.full, full)
+			assert.Equal(t, tt.want, q.events)
+		})
+	}
+}
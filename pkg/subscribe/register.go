@@ -12,14 +12,14 @@ func DefaultGetter(apiOp *types2.APIRequest) *types2.APISchemas {
 	return apiOp.Schemas
 }
 
-func Register(schemas *types2.APISchemas, getter SchemasGetter, serverVersion string) {
+func Register(schemas *types2.APISchemas, getter SchemasGetter, serverVersion string, options Options) {
 	if getter == nil {
 		getter = DefaultGetter
 	}
 	schemas.MustImportAndCustomize(Subscribe{}, func(schema *types2.APISchema) {
 		schema.CollectionMethods = []string{http.MethodGet}
 		schema.ResourceMethods = []string{}
-		schema.ListHandler = NewHandler(getter, serverVersion)
+		schema.ListHandler = NewHandler(getter, serverVersion, options)
 		schema.PluralName = "subscribe"
 	})
 }
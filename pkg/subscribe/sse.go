@@ -0,0 +1,123 @@
+package subscribe
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/apierror"
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/validation"
+)
+
+const eventStreamContentType = "text/event-stream"
+
+func isEventStream(req *http.Request) bool {
+	return strings.Contains(req.Header.Get("Accept"), eventStreamContentType)
+}
+
+// subscribeFromQuery reads a subscription from the query parameters of an event stream request. A reconnecting
+// client sends the id of the last event it received as Last-Event-ID, which takes precedence over resourceVersion.
+func subscribeFromQuery(req *http.Request) (Subscribe, error) {
+	query := req.URL.Query()
+	sub := Subscribe{
+		ResourceType:    query.Get("resourceType"),
+		ResourceVersion: query.Get("resourceVersion"),
+		Namespace:       query.Get("namespace"),
+		ID:              query.Get("id"),
+		Selector:        query.Get("selector"),
+		FieldSelector:   query.Get("fieldSelector"),
+	}
+	if lastEventID := req.Header.Get("Last-Event-ID"); lastEventID != "" {
+		sub.ResourceVersion = lastEventID
+	}
+	if sub.ResourceType == "" {
+		return sub, apierror.NewAPIError(validation.MissingRequired, "resourceType is required")
+	}
+	return sub, nil
+}
+
+// eventID returns the SSE id of an event. Only revisions that are safe to resume from are used, other events
+// leave the last id the client saw unchanged.
+func eventID(event types.APIEvent) string {
+	switch event.Name {
+	case types.BookmarkAPIEvent, "resource.stop", "resource.overflow":
+		return event.Revision
+	}
+	return ""
+}
+
+func sseHandler(apiOp *types.APIRequest, getter SchemasGetter, serverVersion string, options Options) error {
+	sub, err := subscribeFromQuery(apiOp.Request)
+	if err != nil {
+		apiOp.WriteError(err)
+		return nil
+	}
+
+	flusher, ok := apiOp.Response.(http.Flusher)
+	if !ok {
+		return fmt.Errorf("response writer does not support streaming")
+	}
+
+	apiOp.Response.Header().Set("Content-Type", eventStreamContentType)
+	apiOp.Response.Header().Set("Cache-Control", "no-cache")
+	apiOp.Response.Header().Set("X-Accel-Buffering", "no")
+	apiOp.Response.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	watches := NewWatchSession(apiOp, getter, options)
+	defer watches.Close()
+
+	events := watches.WatchSubscription(sub)
+	t := time.NewTicker(30 * time.Second)
+	defer t.Stop()
+	defer func() {
+		// Ensure that events gets fully consumed
+		go func() {
+			for range events {
+			}
+		}()
+	}()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := writeEvent(apiOp, getter, flusher, event); err != nil {
+				return err
+			}
+		case <-t.C:
+			if err := writeEvent(apiOp, getter, flusher, types.APIEvent{
+				Name: "ping",
+				Object: types.APIObject{
+					Object: map[string]interface{}{"version": serverVersion},
+				},
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeEvent(apiOp *types.APIRequest, getter SchemasGetter, flusher http.Flusher, event types.APIEvent) error {
+	event = toWireEvent(apiOp, getter, event)
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if id := eventID(event); id != "" {
+		if _, err := fmt.Fprintf(apiOp.Response, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(apiOp.Response, "data: %s\n\n", data); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
@@ -0,0 +1,60 @@
+package subscribe
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeFromQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		lastEventID string
+		want        Subscribe
+		wantErr     bool
+	}{
+		{
+			name: "query parameters",
+			url:  "/v1/subscribe?resourceType=pod&namespace=default&selector=app%3Dweb&fieldSelector=spec.nodeName%3Dnode1&resourceVersion=10",
+			want: Subscribe{
+				ResourceType:    "pod",
+				Namespace:       "default",
+				Selector:        "app=web",
+				FieldSelector:   "spec.nodeName=node1",
+				ResourceVersion: "10",
+			},
+		},
+		{
+			name:        "last event id resumes",
+			url:         "/v1/subscribe?resourceType=pod&resourceVersion=10",
+			lastEventID: "25",
+			want: Subscribe{
+				ResourceType:    "pod",
+				ResourceVersion: "25",
+			},
+		},
+		{
+			name:    "resource type is required",
+			url:     "/v1/subscribe?namespace=default",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			req.Header.Set("Accept", eventStreamContentType)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			got, err := subscribeFromQuery(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/acorn-io/brent/pkg/types"
 	"github.com/gorilla/websocket"
@@ -15,26 +16,41 @@ type WatchSession struct {
 
 	apiOp    *types.APIRequest
 	getter   SchemasGetter
-	watchers map[string]func()
+	options  Options
+	watchers map[string]*watcher
 	wg       sync.WaitGroup
 	ctx      context.Context
 	cancel   func()
 }
 
-func (s *WatchSession) stop(sub Subscribe, resp chan<- types.APIEvent) {
+type watcher struct {
+	cancel func()
+}
+
+func (s *WatchSession) stop(sub Subscribe) {
 	s.Lock()
 	defer s.Unlock()
-	if cancel, ok := s.watchers[sub.key()]; ok {
-		cancel()
-		resp <- types.APIEvent{
-			Name:         "resource.stop",
-			ResourceType: sub.ResourceType,
-			Namespace:    sub.Namespace,
-			ID:           sub.ID,
-			Selector:     sub.Selector,
-		}
+	if w, ok := s.watchers[sub.key()]; ok {
+		w.cancel()
+	}
+}
+
+// finish removes the watcher and tells the client the revision it can resubscribe from without missing events.
+func (s *WatchSession) finish(sub Subscribe, w *watcher, revision string, resp chan<- types.APIEvent) {
+	s.Lock()
+	defer s.Unlock()
+	if s.watchers[sub.key()] == w {
+		delete(s.watchers, sub.key())
+	}
+	resp <- types.APIEvent{
+		Name:          "resource.stop",
+		ResourceType:  sub.ResourceType,
+		Namespace:     sub.Namespace,
+		ID:            sub.ID,
+		Selector:      sub.Selector,
+		FieldSelector: sub.FieldSelector,
+		Revision:      revision,
 	}
-	delete(s.watchers, sub.key())
 }
 
 func (s *WatchSession) add(sub Subscribe, resp chan<- types.APIEvent) {
@@ -42,20 +58,28 @@ func (s *WatchSession) add(sub Subscribe, resp chan<- types.APIEvent) {
 	defer s.Unlock()
 
 	ctx, cancel := context.WithCancel(s.ctx)
-	s.watchers[sub.key()] = cancel
+	w := &watcher{cancel: cancel}
+	s.watchers[sub.key()] = w
 
 	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done()
-		defer s.stop(sub, resp)
 
-		if err := s.stream(ctx, sub, resp); err != nil {
+		revision := sub.ResourceVersion
+		defer func() {
+			cancel()
+			s.finish(sub, w, revision, resp)
+		}()
+
+		if err := s.stream(ctx, sub, resp, &revision); err != nil {
 			sendErr(resp, err, sub)
 		}
 	}()
 }
 
-func (s *WatchSession) stream(ctx context.Context, sub Subscribe, result chan<- types.APIEvent) error {
+// stream forwards the events of sub to result. revision is updated with every bookmark, which is the last
+// point the subscription can be resumed from.
+func (s *WatchSession) stream(ctx context.Context, sub Subscribe, result chan<- types.APIEvent, revision *string) error {
 	schemas := s.getter(s.apiOp)
 	schema := schemas.LookupSchema(sub.ResourceType)
 	if schema == nil {
@@ -72,53 +96,171 @@ func (s *WatchSession) stream(ctx context.Context, sub Subscribe, result chan<-
 	apiOp.Namespace = sub.Namespace
 	apiOp.Schemas = schemas
 	c, err := schema.Store.Watch(apiOp, schema, types.WatchRequest{
-		Revision: sub.ResourceVersion,
-		ID:       sub.ID,
-		Selector: sub.Selector,
+		Revision:      sub.ResourceVersion,
+		ID:            sub.ID,
+		Selector:      sub.Selector,
+		FieldSelector: sub.FieldSelector,
 	})
 	if err != nil {
 		return err
 	}
 
 	result <- types.APIEvent{
-		Name:         "resource.start",
-		ResourceType: sub.ResourceType,
-		ID:           sub.ID,
-		Selector:     sub.Selector,
+		Name:          "resource.start",
+		ResourceType:  sub.ResourceType,
+		ID:            sub.ID,
+		Selector:      sub.Selector,
+		FieldSelector: sub.FieldSelector,
 	}
 
 	if c == nil {
 		<-s.apiOp.Context().Done()
+		return nil
+	}
+
+	var overflow bool
+	if s.options.Overflow == OverflowCoalesce {
+		overflow = s.coalesce(ctx, sub, c, result, revision)
 	} else {
-		for event := range c {
-			if event.Error == nil {
-				event.ID = sub.ID
-				event.Selector = sub.Selector
+		overflow = s.forward(ctx, sub, c, result, revision)
+	}
+	if overflow {
+		overflows.WithLabelValues("disconnected").Inc()
+		go func() {
+			for range c {
+				// continue to drain until close
+			}
+		}()
+		result <- types.APIEvent{
+			Name:          "resource.overflow",
+			ResourceType:  sub.ResourceType,
+			Namespace:     sub.Namespace,
+			ID:            sub.ID,
+			Selector:      sub.Selector,
+			FieldSelector: sub.FieldSelector,
+			Revision:      *revision,
+		}
+	}
+
+	return nil
+}
+
+func toSubscriptionEvent(sub Subscribe, event types.APIEvent) types.APIEvent {
+	if event.Error != nil {
+		return errEvent(event.Error, sub)
+	}
+	event.ID = sub.ID
+	event.Selector = sub.Selector
+	event.FieldSelector = sub.FieldSelector
+	if event.Name == types.BookmarkAPIEvent {
+		event.ResourceType = sub.ResourceType
+		event.Namespace = sub.Namespace
+	}
+	return event
+}
+
+// sent records the revision of bookmarks once the client has them, events queued behind a bookmark that was
+// not delivered must be replayed on resume.
+func sent(event types.APIEvent, revision *string) {
+	if event.Name == types.BookmarkAPIEvent {
+		*revision = event.Revision
+	}
+}
+
+// forward sends events until the client falls behind and returns true if the subscription overflowed.
+func (s *WatchSession) forward(ctx context.Context, sub Subscribe, c chan types.APIEvent, result chan<- types.APIEvent, revision *string) bool {
+	for event := range c {
+		event = toSubscriptionEvent(sub, event)
+		select {
+		case result <- event:
+			sent(event, revision)
+			continue
+		default:
+		}
+
+		if s.options.Overflow != OverflowBlock {
+			return true
+		}
+
+		overflows.WithLabelValues("blocked").Inc()
+		timer := time.NewTimer(s.options.BlockTimeout)
+		select {
+		case result <- event:
+			timer.Stop()
+			sent(event, revision)
+		case <-timer.C:
+			overflows.WithLabelValues("block_timeout").Inc()
+			return true
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		}
+	}
+	return false
+}
+
+// coalesce queues events the client can not take yet, merging events for the same object, and returns true if
+// the queue filled up.
+func (s *WatchSession) coalesce(ctx context.Context, sub Subscribe, c chan types.APIEvent, result chan<- types.APIEvent, revision *string) bool {
+	queue := coalesceQueue{
+		limit: s.options.BufferSize,
+	}
+
+	for {
+		var (
+			out  chan<- types.APIEvent
+			next types.APIEvent
+		)
+		if queue.len() > 0 {
+			out = result
+			next = queue.peek()
+		}
+
+		select {
+		case event, ok := <-c:
+			if !ok {
+				return s.flush(ctx, &queue, result, revision)
+			}
+			event = toSubscriptionEvent(sub, event)
+			if queue.len() == 0 {
 				select {
 				case result <- event:
+					sent(event, revision)
+					continue
 				default:
-					// handle slow consumer
-					go func() {
-						for range c {
-							// continue to drain until close
-						}
-					}()
-					return nil
 				}
-			} else {
-				sendErr(result, event.Error, sub)
 			}
+			if !queue.push(event) {
+				return true
+			}
+		case out <- next:
+			queue.pop()
+			sent(next, revision)
+		case <-ctx.Done():
+			return false
 		}
 	}
+}
 
-	return nil
+func (s *WatchSession) flush(ctx context.Context, queue *coalesceQueue, result chan<- types.APIEvent, revision *string) bool {
+	for queue.len() > 0 {
+		select {
+		case result <- queue.peek():
+			sent(queue.peek(), revision)
+			queue.pop()
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return false
 }
 
-func NewWatchSession(apiOp *types.APIRequest, getter SchemasGetter) *WatchSession {
+func NewWatchSession(apiOp *types.APIRequest, getter SchemasGetter, options Options) *WatchSession {
 	ws := &WatchSession{
 		apiOp:    apiOp,
 		getter:   getter,
-		watchers: map[string]func(){},
+		options:  options.withDefaults(),
+		watchers: map[string]*watcher{},
 	}
 
 	ws.ctx, ws.cancel = context.WithCancel(apiOp.Request.Context())
@@ -126,7 +268,7 @@ func NewWatchSession(apiOp *types.APIRequest, getter SchemasGetter) *WatchSessio
 }
 
 func (s *WatchSession) Watch(conn *websocket.Conn) <-chan types.APIEvent {
-	result := make(chan types.APIEvent, 100)
+	result := make(chan types.APIEvent, s.options.BufferSize)
 	go func() {
 		defer close(result)
 
@@ -137,6 +279,17 @@ func (s *WatchSession) Watch(conn *websocket.Conn) <-chan types.APIEvent {
 	return result
 }
 
+// WatchSubscription streams a single subscription, the returned channel is closed once it stops.
+func (s *WatchSession) WatchSubscription(sub Subscribe) <-chan types.APIEvent {
+	result := make(chan types.APIEvent, s.options.BufferSize)
+	s.add(sub, result)
+	go func() {
+		defer close(result)
+		s.wg.Wait()
+	}()
+	return result
+}
+
 func (s *WatchSession) Close() {
 	s.cancel()
 	s.wg.Wait()
@@ -160,7 +313,7 @@ func (s *WatchSession) watch(conn *websocket.Conn, resp chan types.APIEvent) err
 		}
 
 		if sub.Stop {
-			s.stop(sub, resp)
+			s.stop(sub)
 		} else {
 			s.Lock()
 			_, ok := s.watchers[sub.key()]
@@ -173,11 +326,16 @@ func (s *WatchSession) watch(conn *websocket.Conn, resp chan types.APIEvent) err
 }
 
 func sendErr(resp chan<- types.APIEvent, err error, sub Subscribe) {
-	resp <- types.APIEvent{
-		ResourceType: sub.ResourceType,
-		Namespace:    sub.Namespace,
-		ID:           sub.ID,
-		Selector:     sub.Selector,
-		Error:        err,
+	resp <- errEvent(err, sub)
+}
+
+func errEvent(err error, sub Subscribe) types.APIEvent {
+	return types.APIEvent{
+		ResourceType:  sub.ResourceType,
+		Namespace:     sub.Namespace,
+		ID:            sub.ID,
+		Selector:      sub.Selector,
+		FieldSelector: sub.FieldSelector,
+		Error:         err,
 	}
 }
@@ -0,0 +1,176 @@
+package summary
+
+import (
+	"github.com/acorn-io/schemer/data"
+	"github.com/acorn-io/schemer/data/convert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// selectorTargets is the kind selected by the label selector of a kind, the selector of a workload matches its
+// pods, except for a Deployment which selects ReplicaSets that in turn select the pods.
+var selectorTargets = map[string]struct {
+	sourceGroup string
+	apiVersion  string
+	kind        string
+}{
+	"Deployment":  {"apps", "apps/v1", "ReplicaSet"},
+	"ReplicaSet":  {"apps", "v1", "Pod"},
+	"StatefulSet": {"apps", "v1", "Pod"},
+	"DaemonSet":   {"apps", "v1", "Pod"},
+	"Job":         {"batch", "v1", "Pod"},
+}
+
+func checkRelationships(obj data.Object, _ []Condition, summary Summary) Summary {
+	summary.Relationships = append(summary.Relationships, ownerRelationships(obj)...)
+	summary.Relationships = append(summary.Relationships, selectorRelationships(obj)...)
+	summary.Relationships = append(summary.Relationships, referenceRelationships(obj)...)
+	return summary
+}
+
+// ownerRelationships returns the owners in the namespace of the dependent, owner references do not tell whether the
+// owner is cluster scoped. Consumers that know the schema of the owner must drop the namespace of cluster scoped
+// owners.
+func ownerRelationships(obj data.Object) (result []Relationship) {
+	namespace := obj.String("metadata", "namespace")
+	for _, ref := range obj.Slice("metadata", "ownerReferences") {
+		result = append(result, Relationship{
+			Name:         ref.String("name"),
+			Namespace:    namespace,
+			ControlledBy: ref.Bool("controller"),
+			Kind:         ref.String("kind"),
+			APIVersion:   ref.String("apiVersion"),
+			Inbound:      true,
+			Type:         "owner",
+		})
+	}
+	return
+}
+
+func selectorRelationships(obj data.Object) []Relationship {
+	namespace := obj.String("metadata", "namespace")
+
+	if kind(obj, "", "Service") {
+		matchLabels := map[string]string{}
+		for k, v := range obj.Map("spec", "selector") {
+			matchLabels[k] = convert.ToString(v)
+		}
+		if len(matchLabels) == 0 {
+			return nil
+		}
+		return []Relationship{{
+			Namespace:  namespace,
+			Kind:       "Pod",
+			APIVersion: "v1",
+			Type:       "selects",
+			Selector: &metav1.LabelSelector{
+				MatchLabels: matchLabels,
+			},
+		}}
+	}
+
+	for sourceKind, target := range selectorTargets {
+		if !kind(obj, target.sourceGroup, sourceKind) {
+			continue
+		}
+		selector := &metav1.LabelSelector{}
+		if err := convert.ToObj(obj.Map("spec", "selector"), selector); err != nil || isEmptySelector(selector) {
+			return nil
+		}
+		return []Relationship{{
+			Namespace:  namespace,
+			Kind:       target.kind,
+			APIVersion: target.apiVersion,
+			Type:       "selects",
+			Selector:   selector,
+		}}
+	}
+
+	return nil
+}
+
+func isEmptySelector(selector *metav1.LabelSelector) bool {
+	return len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0
+}
+
+// podSpec returns the pod spec of a pod or the pod template of a workload.
+func podSpec(obj data.Object) data.Object {
+	switch {
+	case kind(obj, "", "Pod"):
+		return obj.Map("spec")
+	case kind(obj, "batch", "CronJob"):
+		return obj.Map("spec", "jobTemplate", "spec", "template", "spec")
+	}
+	for sourceKind, target := range selectorTargets {
+		if kind(obj, target.sourceGroup, sourceKind) {
+			return obj.Map("spec", "template", "spec")
+		}
+	}
+	return nil
+}
+
+func referenceRelationships(obj data.Object) []Relationship {
+	var (
+		namespace = obj.String("metadata", "namespace")
+		seen      = map[Relationship]bool{}
+		result    []Relationship
+	)
+
+	add := func(apiVersion, targetKind, name string) {
+		if name == "" {
+			return
+		}
+		rel := Relationship{
+			Name:       name,
+			Namespace:  namespace,
+			Kind:       targetKind,
+			APIVersion: apiVersion,
+			Type:       "uses",
+		}
+		if targetKind == "PersistentVolume" {
+			rel.Namespace = ""
+		}
+		if !seen[rel] {
+			seen[rel] = true
+			result = append(result, rel)
+		}
+	}
+
+	if kind(obj, "", "PersistentVolumeClaim") {
+		add("v1", "PersistentVolume", obj.String("spec", "volumeName"))
+		return result
+	}
+
+	spec := podSpec(obj)
+	if spec == nil {
+		return nil
+	}
+
+	add("v1", "ServiceAccount", spec.String("serviceAccountName"))
+	for _, secret := range spec.Slice("imagePullSecrets") {
+		add("v1", "Secret", secret.String("name"))
+	}
+
+	for _, volume := range spec.Slice("volumes") {
+		add("v1", "Secret", volume.String("secret", "secretName"))
+		add("v1", "ConfigMap", volume.String("configMap", "name"))
+		add("v1", "PersistentVolumeClaim", volume.String("persistentVolumeClaim", "claimName"))
+		for _, source := range volume.Slice("projected", "sources") {
+			add("v1", "Secret", source.String("secret", "name"))
+			add("v1", "ConfigMap", source.String("configMap", "name"))
+		}
+	}
+
+	containers := append(spec.Slice("initContainers"), spec.Slice("containers")...)
+	for _, container := range containers {
+		for _, env := range container.Slice("env") {
+			add("v1", "Secret", env.String("valueFrom", "secretKeyRef", "name"))
+			add("v1", "ConfigMap", env.String("valueFrom", "configMapKeyRef", "name"))
+		}
+		for _, envFrom := range container.Slice("envFrom") {
+			add("v1", "Secret", envFrom.String("secretRef", "name"))
+			add("v1", "ConfigMap", envFrom.String("configMapRef", "name"))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,173 @@
+package summary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRelationships(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  map[string]interface{}
+		want []Relationship
+	}{
+		{
+			name: "owner references",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "ReplicaSet",
+				"metadata": map[string]interface{}{
+					"namespace": "default",
+					"ownerReferences": []interface{}{
+						map[string]interface{}{
+							"apiVersion": "apps/v1",
+							"kind":       "Deployment",
+							"name":       "web",
+							"controller": true,
+						},
+					},
+				},
+			},
+			want: []Relationship{
+				{Name: "web", Namespace: "default", ControlledBy: true, Kind: "Deployment", APIVersion: "apps/v1", Inbound: true, Type: "owner"},
+			},
+		},
+		{
+			name: "service selects pods",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata": map[string]interface{}{
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"selector": map[string]interface{}{"app": "web"},
+				},
+			},
+			want: []Relationship{
+				{Namespace: "default", Kind: "Pod", APIVersion: "v1", Type: "selects", Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "web"},
+				}},
+			},
+		},
+		{
+			name: "deployment selects replicasets and uses references of its template",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"selector": map[string]interface{}{
+						"matchLabels": map[string]interface{}{"app": "web"},
+					},
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"volumes": []interface{}{
+								map[string]interface{}{"secret": map[string]interface{}{"secretName": "tls"}},
+								map[string]interface{}{"persistentVolumeClaim": map[string]interface{}{"claimName": "data"}},
+							},
+							"containers": []interface{}{
+								map[string]interface{}{
+									"envFrom": []interface{}{
+										map[string]interface{}{"configMapRef": map[string]interface{}{"name": "settings"}},
+									},
+									"env": []interface{}{
+										map[string]interface{}{
+											"valueFrom": map[string]interface{}{
+												"secretKeyRef": map[string]interface{}{"name": "tls"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: []Relationship{
+				{Namespace: "default", Kind: "ReplicaSet", APIVersion: "apps/v1", Type: "selects", Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "web"},
+				}},
+				{Name: "tls", Namespace: "default", Kind: "Secret", APIVersion: "v1", Type: "uses"},
+				{Name: "data", Namespace: "default", Kind: "PersistentVolumeClaim", APIVersion: "v1", Type: "uses"},
+				{Name: "settings", Namespace: "default", Kind: "ConfigMap", APIVersion: "v1", Type: "uses"},
+			},
+		},
+		{
+			name: "bound claim uses cluster scoped volume",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "PersistentVolumeClaim",
+				"metadata": map[string]interface{}{
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"volumeName": "pv-1",
+				},
+			},
+			want: []Relationship{
+				{Name: "pv-1", Kind: "PersistentVolume", APIVersion: "v1", Type: "uses"},
+			},
+		},
+		{
+			name: "succeeded pod keeps its owner",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata": map[string]interface{}{
+					"namespace": "default",
+					"ownerReferences": []interface{}{
+						map[string]interface{}{
+							"apiVersion": "batch/v1",
+							"kind":       "Job",
+							"name":       "migrate",
+							"controller": true,
+						},
+					},
+				},
+				"status": map[string]interface{}{
+					"phase": "Succeeded",
+				},
+			},
+			want: []Relationship{
+				{Name: "migrate", Namespace: "default", ControlledBy: true, Kind: "Job", APIVersion: "batch/v1", Inbound: true, Type: "owner"},
+			},
+		},
+		{
+			name: "complete job keeps its owner",
+			obj: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"metadata": map[string]interface{}{
+					"namespace": "default",
+					"ownerReferences": []interface{}{
+						map[string]interface{}{
+							"apiVersion": "batch/v1",
+							"kind":       "CronJob",
+							"name":       "nightly",
+							"controller": true,
+						},
+					},
+				},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Complete", "status": "True"},
+					},
+				},
+			},
+			want: []Relationship{
+				{Name: "nightly", Namespace: "default", ControlledBy: true, Kind: "CronJob", APIVersion: "batch/v1", Inbound: true, Type: "owner"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Summarize(&unstructured.Unstructured{Object: tt.obj}).Relationships)
+		})
+	}
+}
@@ -1,12 +1,287 @@
 package summary
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/acorn-io/schemer/data"
+	"github.com/acorn-io/schemer/data/convert"
 )
 
 var (
-	Summarizers          []Summarizer
-	ConditionSummarizers []Summarizer
+	Summarizers = []Summarizer{
+		checkRelationships,
+		checkConditions,
+		checkPod,
+		checkWorkload,
+		checkJob,
+		checkPVC,
+		checkRemoving,
+	}
+	ConditionSummarizers = []Summarizer{
+		checkConditions,
+	}
+
+	// errorWaitingReasons are container waiting reasons that will not resolve without a change to the pod
+	errorWaitingReasons = map[string]bool{
+		"CrashLoopBackOff":           true,
+		"CreateContainerConfigError": true,
+		"CreateContainerError":       true,
+		"ErrImagePull":               true,
+		"ImagePullBackOff":           true,
+		"InvalidImageName":           true,
+		"RunContainerError":          true,
+	}
 )
 
 type Summarizer func(obj data.Object, conditions []Condition, summary Summary) Summary
+
+func kind(obj data.Object, group, kind string) bool {
+	apiVersion := obj.String("apiVersion")
+	if group == "" {
+		if apiVersion != "v1" {
+			return false
+		}
+	} else if !strings.HasPrefix(apiVersion, group+"/") {
+		return false
+	}
+	return obj.String("kind") == kind
+}
+
+func number(obj data.Object, names ...string) int64 {
+	n, _ := convert.ToNumber(data.GetValueN(obj, names...))
+	return n
+}
+
+// checkConditions handles the Ready, Available and Progressing conditions most controllers set, as well as the
+// Failed and Stalled conditions that report a terminal error.
+func checkConditions(_ data.Object, conditions []Condition, summary Summary) Summary {
+	for _, c := range conditions {
+		switch c.Type() {
+		case "Ready", "Available":
+			switch c.Status() {
+			case "False", "Unknown":
+				summary.Transitioning = true
+			default:
+				continue
+			}
+		case "Progressing":
+			switch {
+			case c.Status() == "False":
+				summary.Error = true
+			case c.Status() == "Unknown":
+				summary.Transitioning = true
+			default:
+				continue
+			}
+		case "Failed", "Stalled":
+			if c.Status() != "True" {
+				continue
+			}
+			summary.Error = true
+		default:
+			continue
+		}
+		if c.Message() != "" {
+			summary.Message = append(summary.Message, c.Message())
+		}
+	}
+	switch {
+	case summary.Error:
+		summary.State = "error"
+	case summary.Transitioning:
+		summary.State = "in-progress"
+	}
+	return summary
+}
+
+func checkPod(obj data.Object, _ []Condition, summary Summary) Summary {
+	if !kind(obj, "", "Pod") {
+		return summary
+	}
+
+	phase := obj.String("status", "phase")
+	switch phase {
+	case "Succeeded":
+		// a completed pod is no longer ready, which is not an error
+		summary.State = "succeeded"
+		summary.Error = false
+		summary.Transitioning = false
+		return summary
+	case "Failed":
+		summary.State = "failed"
+		summary.Error = true
+		summary.Transitioning = false
+		if msg := obj.String("status", "message"); msg != "" {
+			summary.Message = append(summary.Message, msg)
+		}
+		return summary
+	case "Pending":
+		summary.State = "pending"
+		summary.Transitioning = true
+	case "Running":
+		summary.State = "running"
+	case "Unknown":
+		summary.State = "unknown"
+		summary.Transitioning = true
+	}
+
+	statuses := append(obj.Slice("status", "initContainerStatuses"), obj.Slice("status", "containerStatuses")...)
+	for _, status := range statuses {
+		reason := status.String("state", "waiting", "reason")
+		if reason == "" {
+			continue
+		}
+		if errorWaitingReasons[reason] {
+			summary.Error = true
+		} else {
+			summary.Transitioning = true
+		}
+		summary.State = reason
+		if msg := status.String("state", "waiting", "message"); msg != "" {
+			summary.Message = append(summary.Message, fmt.Sprintf("%s: %s", status.String("name"), msg))
+		}
+	}
+
+	return summary
+}
+
+func rollout(summary Summary, desired, updated, ready int64, noun string) Summary {
+	switch {
+	case updated < desired:
+		summary.State = "updating"
+		summary.Transitioning = true
+		summary.Message = append(summary.Message, fmt.Sprintf("%d of %d %s updated", updated, desired, noun))
+	case ready < desired:
+		summary.State = "updating"
+		summary.Transitioning = true
+		summary.Message = append(summary.Message, fmt.Sprintf("%d of %d %s ready", ready, desired, noun))
+	case !summary.Error:
+		summary.State = "active"
+		summary.Transitioning = false
+	}
+	return summary
+}
+
+func checkWorkload(obj data.Object, _ []Condition, summary Summary) Summary {
+	var (
+		isDeployment  = kind(obj, "apps", "Deployment")
+		isStatefulSet = kind(obj, "apps", "StatefulSet")
+		isDaemonSet   = kind(obj, "apps", "DaemonSet")
+	)
+	if !isDeployment && !isStatefulSet && !isDaemonSet {
+		return summary
+	}
+
+	if observed := number(obj, "status", "observedGeneration"); observed < number(obj, "metadata", "generation") {
+		summary.State = "updating"
+		summary.Transitioning = true
+		return summary
+	}
+
+	if isDaemonSet {
+		return rollout(summary,
+			number(obj, "status", "desiredNumberScheduled"),
+			number(obj, "status", "updatedNumberScheduled"),
+			number(obj, "status", "numberAvailable"),
+			"pods")
+	}
+
+	desired := int64(1)
+	if _, ok := data.GetValue(obj, "spec", "replicas"); ok {
+		desired = number(obj, "spec", "replicas")
+	}
+
+	if isDeployment {
+		if obj.Bool("spec", "paused") {
+			summary.State = "paused"
+			return summary
+		}
+		return rollout(summary,
+			desired,
+			number(obj, "status", "updatedReplicas"),
+			number(obj, "status", "availableReplicas"),
+			"replicas")
+	}
+
+	updated := number(obj, "status", "updatedReplicas")
+	if obj.String("status", "currentRevision") == obj.String("status", "updateRevision") ||
+		obj.String("spec", "updateStrategy", "type") == "OnDelete" {
+		// OnDelete leaves replicas on the old revision until they are deleted, that is not a rollout in progress
+		updated = desired
+	}
+	return rollout(summary,
+		desired,
+		updated,
+		number(obj, "status", "readyReplicas"),
+		"replicas")
+}
+
+func checkJob(obj data.Object, conditions []Condition, summary Summary) Summary {
+	if !kind(obj, "batch", "Job") {
+		return summary
+	}
+
+	for _, c := range conditions {
+		if c.Status() != "True" {
+			continue
+		}
+		switch c.Type() {
+		case "Complete":
+			summary.State = "succeeded"
+			summary.Error = false
+			summary.Transitioning = false
+			return summary
+		case "Failed":
+			summary.State = "failed"
+			summary.Error = true
+			return summary
+		}
+	}
+
+	if obj.Bool("spec", "suspend") {
+		summary.State = "suspended"
+		return summary
+	}
+
+	completions := int64(1)
+	if _, ok := data.GetValue(obj, "spec", "completions"); ok {
+		completions = number(obj, "spec", "completions")
+	}
+	summary.State = "running"
+	summary.Transitioning = true
+	summary.Message = append(summary.Message, fmt.Sprintf("%d of %d completions succeeded", number(obj, "status", "succeeded"), completions))
+	return summary
+}
+
+func checkPVC(obj data.Object, _ []Condition, summary Summary) Summary {
+	if !kind(obj, "", "PersistentVolumeClaim") {
+		return summary
+	}
+
+	switch obj.String("status", "phase") {
+	case "Bound":
+		summary.State = "bound"
+	case "Lost":
+		summary.State = "lost"
+		summary.Error = true
+		summary.Message = append(summary.Message, "the bound volume no longer exists")
+	default:
+		summary.State = "pending"
+		summary.Transitioning = true
+	}
+	return summary
+}
+
+func checkRemoving(obj data.Object, _ []Condition, summary Summary) Summary {
+	if obj.String("metadata", "deletionTimestamp") == "" {
+		return summary
+	}
+
+	summary.State = "removing"
+	summary.Transitioning = true
+	if finalizers := obj.StringSlice("metadata", "finalizers"); len(finalizers) > 0 {
+		summary.Message = append(summary.Message, "waiting on "+strings.Join(finalizers, ", "))
+	}
+	return summary
+}
@@ -0,0 +1,293 @@
+package summary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSummarize(t *testing.T) {
+	condition := func(conditionType, status, message string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":    conditionType,
+			"status":  status,
+			"message": message,
+		}
+	}
+
+	tests := []struct {
+		name string
+		obj  map[string]interface{}
+		want Summary
+	}{
+		{
+			name: "no status is active",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+			},
+			want: Summary{State: "active"},
+		},
+		{
+			name: "ready condition false",
+			obj: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						condition("Ready", "False", "waiting for backend"),
+					},
+				},
+			},
+			want: Summary{State: "in-progress", Transitioning: true, Message: []string{"waiting for backend"}},
+		},
+		{
+			name: "stalled condition",
+			obj: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						condition("Ready", "True", ""),
+						condition("Stalled", "True", "invalid spec"),
+					},
+				},
+			},
+			want: Summary{State: "error", Error: true, Message: []string{"invalid spec"}},
+		},
+		{
+			name: "running pod",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"status": map[string]interface{}{
+					"phase": "Running",
+					"conditions": []interface{}{
+						condition("Ready", "True", ""),
+					},
+				},
+			},
+			want: Summary{State: "running"},
+		},
+		{
+			name: "pod in crash loop",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"status": map[string]interface{}{
+					"phase": "Running",
+					"containerStatuses": []interface{}{
+						map[string]interface{}{
+							"name": "web",
+							"state": map[string]interface{}{
+								"waiting": map[string]interface{}{
+									"reason":  "CrashLoopBackOff",
+									"message": "back-off 5m0s restarting failed container",
+								},
+							},
+						},
+					},
+				},
+			},
+			want: Summary{State: "crashloopbackoff", Error: true, Message: []string{"web: back-off 5m0s restarting failed container"}},
+		},
+		{
+			name: "pod creating containers",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"status": map[string]interface{}{
+					"phase": "Pending",
+					"containerStatuses": []interface{}{
+						map[string]interface{}{
+							"name": "web",
+							"state": map[string]interface{}{
+								"waiting": map[string]interface{}{
+									"reason": "ContainerCreating",
+								},
+							},
+						},
+					},
+				},
+			},
+			want: Summary{State: "containercreating", Transitioning: true},
+		},
+		{
+			name: "completed pod is not ready but done",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"status": map[string]interface{}{
+					"phase": "Succeeded",
+					"conditions": []interface{}{
+						condition("Ready", "False", ""),
+					},
+				},
+			},
+			want: Summary{State: "succeeded"},
+		},
+		{
+			name: "deployment rolling out",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"generation": int64(2),
+				},
+				"spec": map[string]interface{}{
+					"replicas": int64(3),
+				},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"updatedReplicas":    int64(1),
+					"availableReplicas":  int64(3),
+				},
+			},
+			want: Summary{State: "updating", Transitioning: true, Message: []string{"1 of 3 replicas updated"}},
+		},
+		{
+			name: "deployment progress deadline exceeded",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"spec": map[string]interface{}{
+					"replicas": int64(1),
+				},
+				"status": map[string]interface{}{
+					"updatedReplicas":   int64(1),
+					"availableReplicas": int64(1),
+					"conditions": []interface{}{
+						condition("Progressing", "False", "deadline exceeded"),
+					},
+				},
+			},
+			want: Summary{State: "error", Error: true, Message: []string{"deadline exceeded"}},
+		},
+		{
+			name: "deployment generation not observed",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"generation": int64(3),
+				},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+				},
+			},
+			want: Summary{State: "updating", Transitioning: true},
+		},
+		{
+			name: "statefulset waiting for ready replicas",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"spec": map[string]interface{}{
+					"replicas": int64(3),
+				},
+				"status": map[string]interface{}{
+					"currentRevision": "web-1",
+					"updateRevision":  "web-1",
+					"readyReplicas":   int64(2),
+				},
+			},
+			want: Summary{State: "updating", Transitioning: true, Message: []string{"2 of 3 replicas ready"}},
+		},
+		{
+			name: "daemonset rolled out",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "DaemonSet",
+				"status": map[string]interface{}{
+					"desiredNumberScheduled": int64(2),
+					"updatedNumberScheduled": int64(2),
+					"numberAvailable":        int64(2),
+				},
+			},
+			want: Summary{State: "active"},
+		},
+		{
+			name: "job running",
+			obj: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"spec": map[string]interface{}{
+					"completions": int64(5),
+				},
+				"status": map[string]interface{}{
+					"succeeded": int64(2),
+				},
+			},
+			want: Summary{State: "running", Transitioning: true, Message: []string{"2 of 5 completions succeeded"}},
+		},
+		{
+			name: "job complete",
+			obj: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						condition("Complete", "True", ""),
+					},
+				},
+			},
+			want: Summary{State: "succeeded"},
+		},
+		{
+			name: "job failed",
+			obj: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						condition("Failed", "True", "backoff limit exceeded"),
+					},
+				},
+			},
+			want: Summary{State: "failed", Error: true, Message: []string{"backoff limit exceeded"}},
+		},
+		{
+			name: "pvc pending",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "PersistentVolumeClaim",
+				"status": map[string]interface{}{
+					"phase": "Pending",
+				},
+			},
+			want: Summary{State: "pending", Transitioning: true},
+		},
+		{
+			name: "pvc bound",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "PersistentVolumeClaim",
+				"status": map[string]interface{}{
+					"phase": "Bound",
+				},
+			},
+			want: Summary{State: "bound"},
+		},
+		{
+			name: "removing",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata": map[string]interface{}{
+					"deletionTimestamp": "2023-01-01T00:00:00Z",
+					"finalizers":        []interface{}{"example.com/cleanup"},
+				},
+				"status": map[string]interface{}{
+					"phase": "Running",
+				},
+			},
+			want: Summary{State: "removing", Transitioning: true, Message: []string{"waiting on example.com/cleanup"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Summarize(&unstructured.Unstructured{Object: tt.obj}))
+		})
+	}
+}
@@ -205,26 +205,30 @@ func DefaultByID(store Store, apiOp *APIRequest, schema *APISchema, id string) (
 }
 
 type WatchRequest struct {
-	Revision string
-	ID       string
-	Selector string
+	Revision      string
+	ID            string
+	Selector      string
+	FieldSelector string
 }
 
 var (
 	ChangeAPIEvent = "resource.change"
 	RemoveAPIEvent = "resource.remove"
 	CreateAPIEvent = "resource.create"
+	// BookmarkAPIEvent carries no object, only the revision the watch has reached
+	BookmarkAPIEvent = "resource.bookmark"
 )
 
 type APIEvent struct {
-	Name         string    `json:"name,omitempty"`
-	Namespace    string    `json:"namespace,omitempty"`
-	ResourceType string    `json:"resourceType,omitempty"`
-	ID           string    `json:"id,omitempty"`
-	Selector     string    `json:"selector,omitempty"`
-	Revision     string    `json:"revision,omitempty"`
-	Object       APIObject `json:"-"`
-	Error        error     `json:"-"`
+	Name          string    `json:"name,omitempty"`
+	Namespace     string    `json:"namespace,omitempty"`
+	ResourceType  string    `json:"resourceType,omitempty"`
+	ID            string    `json:"id,omitempty"`
+	Selector      string    `json:"selector,omitempty"`
+	FieldSelector string    `json:"fieldSelector,omitempty"`
+	Revision      string    `json:"revision,omitempty"`
+	Object        APIObject `json:"-"`
+	Error         error     `json:"-"`
 	// Data is the output format of the object
 	Data interface{} `json:"data,omitempty"`
 }
@@ -29,6 +29,10 @@ var (
 	ModifierNotNull ModifierType = "notnull"
 	ModifierIn      ModifierType = "in"
 	ModifierNotIn   ModifierType = "notin"
+	ModifierGT      ModifierType = "gt"
+	ModifierGTE     ModifierType = "gte"
+	ModifierLT      ModifierType = "lt"
+	ModifierLTE     ModifierType = "lte"
 )
 
 type ModifierType string
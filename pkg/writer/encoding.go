@@ -106,6 +106,10 @@ func (j *EncodingResponseWriter) addLinks(schema *types2.APISchema, context *typ
 		rawResource.Links[link] = context.URLBuilder.Link(schema, rawResource.ID, link)
 	}
 	for action := range schema.ActionHandlers {
+		if _, ok := schema.ResourceActions[action]; !ok {
+			// collection actions share the handlers but are not actions of the resource
+			continue
+		}
 		if rawResource.Actions == nil {
 			rawResource.Actions = map[string]string{}
 		}
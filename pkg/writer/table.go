@@ -0,0 +1,184 @@
+package writer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/acorn-io/brent/pkg/attributes"
+	"github.com/acorn-io/brent/pkg/parse"
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer/data/convert"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// TableResponseWriter writes resources as rows of the columns the apiserver reports for their schema, the same
+// columns kubectl get shows. The columns query parameter selects columns by name or by field path, * selects
+// every column.
+type TableResponseWriter struct {
+	EncodingResponseWriter
+	CSV bool
+}
+
+type column struct {
+	Name     string `json:"name"`
+	Format   string `json:"format"`
+	Priority int32  `json:"priority"`
+	Field    string `json:"field"`
+}
+
+func (t *TableResponseWriter) contentType() string {
+	if t.CSV {
+		return "text/csv"
+	}
+	return "text/plain"
+}
+
+func (t *TableResponseWriter) start(apiOp *types.APIRequest, code int) {
+	AddCommonResponseHeader(apiOp)
+	apiOp.Response.Header().Set("content-type", t.contentType())
+	apiOp.Response.WriteHeader(code)
+}
+
+func (t *TableResponseWriter) Write(apiOp *types.APIRequest, code int, obj types.APIObject) {
+	t.start(apiOp, code)
+	if code >= http.StatusBadRequest {
+		fmt.Fprintf(apiOp.Response, "%s\n", convert.ToString(obj.Data()["message"]))
+		return
+	}
+	if err := t.writeRows(apiOp, apiOp.Response, []types.APIObject{obj}); err != nil {
+		logrus.Errorf("failed to write table: %v", err)
+	}
+}
+
+func (t *TableResponseWriter) WriteList(apiOp *types.APIRequest, code int, list types.APIObjectList) {
+	t.start(apiOp, code)
+	if err := t.writeRows(apiOp, apiOp.Response, list.Objects); err != nil {
+		logrus.Errorf("failed to write table: %v", err)
+	}
+}
+
+func (t *TableResponseWriter) writeRows(apiOp *types.APIRequest, writer io.Writer, objs []types.APIObject) error {
+	columns := selectColumns(apiOp)
+
+	var rows [][]string
+	for _, obj := range objs {
+		resource := t.convert(apiOp, obj)
+		if resource == nil {
+			continue
+		}
+		data := resource.APIObject.Data()
+		row := make([]string, 0, len(columns))
+		for _, col := range columns {
+			row = append(row, t.cell(data, col))
+		}
+		rows = append(rows, row)
+	}
+
+	header := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if t.CSV {
+			header = append(header, col.Name)
+		} else {
+			header = append(header, strings.ToUpper(col.Name))
+		}
+	}
+
+	if t.CSV {
+		w := csv.NewWriter(writer)
+		if err := w.Write(header); err != nil {
+			return err
+		}
+		if err := w.WriteAll(rows); err != nil {
+			return err
+		}
+		return w.Error()
+	}
+
+	w := tabwriter.NewWriter(writer, 6, 4, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func (t *TableResponseWriter) cell(data map[string]interface{}, col column) string {
+	value, ok := parse.GetField(data, parse.FieldPath(strings.TrimPrefix(col.Field, "$.")))
+	if !ok || value == nil {
+		if t.CSV {
+			return ""
+		}
+		return "<none>"
+	}
+
+	str := convert.ToString(value)
+	if col.Format == "date" && !t.CSV {
+		if created, err := time.Parse(time.RFC3339, str); err == nil {
+			return duration.HumanDuration(time.Since(created))
+		}
+	}
+	return str
+}
+
+func schemaColumns(apiOp *types.APIRequest) []column {
+	var columns []column
+	if err := convert.ToObj(attributes.Columns(apiOp.Schema), &columns); err != nil || len(columns) == 0 {
+		columns = []column{
+			{Name: "Name", Field: "$.metadata.name"},
+			{Name: "Created", Format: "date", Field: "$.metadata.creationTimestamp"},
+		}
+	}
+	if apiOp.Namespace == "" && attributes.Namespaced(apiOp.Schema) {
+		columns = append([]column{{Name: "Namespace", Field: "$.metadata.namespace"}}, columns...)
+	}
+	return columns
+}
+
+func selectColumns(apiOp *types.APIRequest) []column {
+	columns := schemaColumns(apiOp)
+
+	var names []string
+	for _, value := range apiOp.Query["columns"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		var result []column
+		for _, col := range columns {
+			if col.Priority == 0 {
+				result = append(result, col)
+			}
+		}
+		return result
+	}
+
+	if len(names) == 1 && names[0] == "*" {
+		return columns
+	}
+
+	var result []column
+outer:
+	for _, name := range names {
+		for _, col := range columns {
+			if strings.EqualFold(col.Name, name) {
+				result = append(result, col)
+				continue outer
+			}
+		}
+		result = append(result, column{
+			Name:  name,
+			Field: name,
+		})
+	}
+	return result
+}
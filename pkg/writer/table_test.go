@@ -0,0 +1,85 @@
+package writer
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/acorn-io/brent/pkg/attributes"
+	"github.com/acorn-io/brent/pkg/types"
+	"github.com/acorn-io/schemer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectColumns(t *testing.T) {
+	schema := &types.APISchema{Schema: &schemas.Schema{}}
+	attributes.SetNamespaced(schema, true)
+	attributes.SetColumns(schema, []map[string]interface{}{
+		{"name": "Name", "field": "$.metadata.fields[0]"},
+		{"name": "Ready", "field": "$.metadata.fields[1]"},
+		{"name": "IP", "priority": 1, "field": "$.metadata.fields[5]"},
+	})
+
+	tests := []struct {
+		name      string
+		namespace string
+		columns   string
+		want      []string
+	}{
+		{
+			name:      "priority columns only",
+			namespace: "default",
+			want:      []string{"Name", "Ready"},
+		},
+		{
+			name: "namespace column across namespaces",
+			want: []string{"Namespace", "Name", "Ready"},
+		},
+		{
+			name:      "all columns",
+			namespace: "default",
+			columns:   "*",
+			want:      []string{"Name", "Ready", "IP"},
+		},
+		{
+			name:      "selected by name and field path",
+			namespace: "default",
+			columns:   "ip,name,metadata.labels.app",
+			want:      []string{"IP", "Name", "metadata.labels.app"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiOp := &types.APIRequest{
+				Schema:    schema,
+				Namespace: tt.namespace,
+				Query:     url.Values{},
+			}
+			if tt.columns != "" {
+				apiOp.Query.Set("columns", tt.columns)
+			}
+
+			var names []string
+			for _, col := range selectColumns(apiOp) {
+				names = append(names, col.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestCell(t *testing.T) {
+	data := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"fields": []interface{}{"web-1", "1/1"},
+			"labels": map[string]interface{}{"app": "web"},
+		},
+	}
+
+	table := &TableResponseWriter{}
+	csv := &TableResponseWriter{CSV: true}
+
+	assert.Equal(t, "1/1", table.cell(data, column{Field: "$.metadata.fields[1]"}))
+	assert.Equal(t, "web", table.cell(data, column{Field: "metadata.labels.app"}))
+	assert.Equal(t, "<none>", table.cell(data, column{Field: "$.metadata.fields[5]"}))
+	assert.Equal(t, "", csv.cell(data, column{Field: "$.metadata.fields[5]"}))
+}